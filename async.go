@@ -0,0 +1,247 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// AsyncPolicy determines what happens when an async logger's buffer is full.
+type AsyncPolicy int
+
+const (
+	// AsyncBlock waits for room in the buffer, so no entries are lost.
+	AsyncBlock AsyncPolicy = iota
+	// AsyncDrop drops the entry and counts it. The number of dropped
+	// entries is written as a WARN entry once the buffer drains.
+	AsyncDrop
+)
+
+// WithAsync writes entries to the log file on a background goroutine
+// instead of the calling one. Log calls queue the entry in a buffer of
+// the given size and return immediately. What happens when the buffer is
+// full is set with WithAsyncPolicy, and defaults to AsyncBlock. Close
+// writes any queued entries before closing the log file. Entries are
+// still displayed on the calling goroutine, and LogBatch still writes
+// synchronously. Hooks for queued entries run on another background
+// goroutine once they've been written, so a hook may log without
+// blocking the writer.
+func WithAsync(bufferSize int) Option {
+	return func(l *Logger) {
+		if bufferSize < 1 {
+			bufferSize = 1
+		}
+		l.out.asyncSize = bufferSize
+	}
+}
+
+// WithAsyncPolicy sets what happens when an async logger's buffer is full.
+func WithAsyncPolicy(policy AsyncPolicy) Option {
+	return func(l *Logger) {
+		l.out.asyncPolicy = policy
+	}
+}
+
+// an entry queued to be written by the background writer
+type asyncEntry struct {
+	l      *Logger
+	e      Entry
+	synced chan struct{} // if set, closed once everything queued before it is written
+}
+
+// asyncWriter queues entries and writes them on a background goroutine.
+type asyncWriter struct {
+	entries chan asyncEntry
+	policy  AsyncPolicy
+	dropped atomic.Uint64  // entries dropped since the last report
+	drops   *dropCounts    // output's count of dropped entries
+	seq     *atomic.Uint64 // output's sequence numbers, if enabled
+	seqMu   sync.Mutex     // numbers and queues entries together so they're queued in order
+	mu      sync.RWMutex   // guards closed so entries aren't sent once entries is closed
+	closed  bool           // whether entries has been closed
+	done    sync.WaitGroup // waits for the background writer to exit
+	hooks   *hookQueue     // runs hooks for written entries
+}
+
+// start writing entries on a background goroutine.
+func (o *output) startAsync() {
+	a := &asyncWriter{
+		entries: make(chan asyncEntry, o.asyncSize),
+		policy:  o.asyncPolicy,
+		drops:   &o.drops,
+	}
+	if o.sequence {
+		a.seq = &o.seq
+	}
+	a.hooks = newHookQueue(o.runHooks)
+	o.async = a
+	a.done.Add(1)
+	go func() {
+		defer a.done.Done()
+		var last *Logger // logger of the last entry written
+		for ae := range a.entries {
+			if ae.synced != nil {
+				// entries dropped so far are reported before a flush
+				// returns, even if more entries are queued behind it
+				if last != nil {
+					a.reportDropped(o, last)
+				}
+				// hooks for the entries before it have run too
+				a.hooks.push(hookCall{synced: ae.synced})
+				continue
+			}
+			last = ae.l
+			// only flush once the queue is empty so bursts are
+			// written together.
+			idle := len(a.entries) == 0
+			ae.l.writeNow(ae.e, idle)
+			if idle {
+				a.reportDropped(o, ae.l)
+			}
+		}
+	}()
+}
+
+// write a WARN entry with l's component and ID counting the entries
+// dropped since the last one, if any were.
+func (a *asyncWriter) reportDropped(o *output, l *Logger) {
+	if n := a.dropped.Swap(0); n > 0 {
+		l.writeNow(Entry{
+			Time:      o.now(),
+			Component: l.component,
+			Level:     WARN,
+			Message:   format("dropped %d messages, async buffer full", n),
+			ID:        l.componentID,
+		}, true)
+	}
+}
+
+// queue an entry to be written, blocking or dropping it if the
+// buffer is full depending on the policy.
+func (a *asyncWriter) enqueue(ae asyncEntry) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return
+	}
+	if a.seq != nil {
+		a.seqMu.Lock()
+		defer a.seqMu.Unlock()
+		ae.e.Seq = a.seq.Add(1)
+	}
+	if a.policy == AsyncDrop {
+		select {
+		case a.entries <- ae:
+		default:
+			a.dropped.Add(1)
+			a.drops.async.Add(1)
+		}
+		return
+	}
+	a.entries <- ae
+}
+
+// wait for everything queued so far to be written.
+func (a *asyncWriter) wait() {
+	synced := make(chan struct{})
+	a.mu.RLock()
+	if a.closed {
+		a.mu.RUnlock()
+		return
+	}
+	a.entries <- asyncEntry{synced: synced}
+	a.mu.RUnlock()
+	<-synced
+}
+
+// stop accepting entries and wait for queued ones to be written.
+func (a *asyncWriter) close() {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.entries)
+	}
+	a.mu.Unlock()
+	a.done.Wait()
+	a.hooks.close()
+}
+
+// a call to the hooks queued by the async writer
+type hookCall struct {
+	e      Entry
+	synced chan struct{} // if set, closed once the hooks queued before it have run
+}
+
+// hookQueue runs hooks for entries written by the async writer on a
+// goroutine of their own. if the writer ran them itself, a hook that logs
+// would wait for room in the queue the writer is meant to be draining, so
+// the queue is unbounded for the same reason.
+type hookQueue struct {
+	mu     sync.Mutex
+	ready  sync.Cond // signaled when calls are queued or the queue is closed
+	calls  []hookCall
+	closed bool
+	done   chan struct{} // closed once the goroutine exits
+}
+
+// start running hooks with run
+func newHookQueue(run func(Entry)) *hookQueue {
+	q := &hookQueue{done: make(chan struct{})}
+	q.ready.L = &q.mu
+	go func() {
+		defer close(q.done)
+		for {
+			q.mu.Lock()
+			for len(q.calls) == 0 && !q.closed {
+				q.ready.Wait()
+			}
+			calls := q.calls
+			q.calls = nil
+			q.mu.Unlock()
+			if len(calls) == 0 {
+				return
+			}
+			for _, c := range calls {
+				if c.synced != nil {
+					close(c.synced)
+				} else {
+					run(c.e)
+				}
+			}
+		}
+	}()
+	return q
+}
+
+// queue a call without blocking
+func (q *hookQueue) push(c hookCall) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		if c.synced != nil {
+			close(c.synced)
+		}
+		return
+	}
+	q.calls = append(q.calls, c)
+	q.ready.Signal()
+}
+
+// run the queued calls and stop
+func (q *hookQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.ready.Signal()
+	q.mu.Unlock()
+	<-q.done
+}
+
+// queue the hooks for an entry the async writer has written, if there
+// are any
+func (a *asyncWriter) queueHooks(o *output, e Entry) {
+	o.hookMu.RLock()
+	n := len(o.hooks)
+	o.hookMu.RUnlock()
+	if n > 0 {
+		a.hooks.push(hookCall{e: e})
+	}
+}
@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAsyncHookThatLogsDoesNotDeadlock(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("async", "1", WithSilentConsole(), WithAsync(2))
+	l.OnLevel(ERROR, func(e Entry) {
+		l.Warn("hook saw %s", e.Message)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 100 {
+			l.Error("failed")
+		}
+		l.Flush()
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("logging from a hook deadlocked the async writer")
+	}
+
+	entries := readLog(t, l)
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	var errs, warns int
+	for _, e := range entries {
+		switch e.Level {
+		case ERROR:
+			errs++
+		case WARN:
+			warns++
+		}
+	}
+	if errs != 100 {
+		t.Errorf("got %d errors, want 100", errs)
+	}
+	if warns == 0 {
+		t.Error("hook entries weren't written")
+	}
+}
+
+func TestAsyncFlushWaitsForHooks(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("async", "1", WithSilentConsole(), WithAsync(16))
+	defer l.Close()
+	calls := make(chan Entry, 10)
+	l.OnLevel(WARN, func(e Entry) { calls <- e })
+
+	l.Warn("disk nearly full")
+	l.Flush()
+	select {
+	case e := <-calls:
+		if e.Message != "disk nearly full" {
+			t.Errorf("hook got %q", e.Message)
+		}
+	default:
+		t.Fatal("hook hadn't run by the time Flush returned")
+	}
+}
+
+func TestAsyncBlockLosesNothing(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("async", "1", WithSilentConsole(), WithAsync(4), WithAsyncPolicy(AsyncBlock), WithSequence(true))
+	const goroutines, perGoroutine = 8, 1000
+	var wg sync.WaitGroup
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range perGoroutine {
+				l.Info("entry %d from %d", n, g)
+			}
+		}()
+	}
+	wg.Wait()
+	path := l.FilePath()
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	entries, err := ReadEntries(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != goroutines*perGoroutine {
+		t.Fatalf("got %d entries, want %d", len(entries), goroutines*perGoroutine)
+	}
+	for i, e := range entries {
+		if e.Seq != uint64(i+1) {
+			t.Fatalf("entry %d has sequence number %d, want entries in order", i, e.Seq)
+		}
+	}
+	if d := l.DropStats()[DropAsync]; d != 0 {
+		t.Errorf("%d entries dropped", d)
+	}
+}
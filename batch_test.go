@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLogBatch(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("batch", "1", WithSilentConsole(), WithExtraColumn(true), WithSequence(true))
+	defer l.Close()
+	at := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	l.LogBatch([]Entry{
+		{Time: at, Level: INFO, Message: "first", Fields: map[string]any{"n": 1}},
+		{Level: DEBUG, Message: "below the minimum level"},
+		{Level: "warn", Message: "second", Component: "other", ID: "2"},
+	})
+
+	entries := readLog(t, l)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(entries), entries)
+	}
+	first, second := entries[0], entries[1]
+	if !first.Time.Equal(at) || first.Component != "batch" || first.ID != "1" || first.Fields["n"] != int64(1) {
+		t.Errorf("first entry = %+v", first)
+	}
+	if second.Level != WARN || second.Component != "other" || second.ID != "2" || second.Time.IsZero() {
+		t.Errorf("second entry = %+v", second)
+	}
+	if first.Seq != 1 || second.Seq != 2 {
+		t.Errorf("sequence numbers = %d, %d, want 1, 2", first.Seq, second.Seq)
+	}
+}
@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"testing"
+)
+
+// log b.N entries to a log file in a temporary directory
+func benchmarkInfo(b *testing.B, opts ...Option) {
+	b.Setenv("LOG_DIR", b.TempDir())
+	l := NewLogger("bench", "1", append([]Option{WithSilentConsole()}, opts...)...)
+	defer l.Close()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		l.Info("request handled")
+	}
+}
+
+// each entry is written to the file, one syscall per entry
+func BenchmarkInfoUnbuffered(b *testing.B) {
+	benchmarkInfo(b)
+}
+
+// entries are written once the 64KB buffer fills
+func BenchmarkInfoBuffered(b *testing.B) {
+	benchmarkInfo(b, WithBufferSize(64*1024))
+}
+
+// each entry is flushed to the file as it's logged, which is the default
+func BenchmarkInfoAutoFlush(b *testing.B) {
+	benchmarkInfo(b, WithAutoFlush(true))
+}
+
+// entries are only written when the buffer fills, or on Flush and Close
+func BenchmarkInfoManualFlush(b *testing.B) {
+	benchmarkInfo(b, WithAutoFlush(false))
+}
+
+// a logger displaying and writing to io.Discard, so only the cost of
+// logging is measured
+func discardLogger() *Logger {
+	return NewWriterLogger("bench", "1", io.Discard, WithOutput(io.Discard))
+}
+
+// log a formatted message the way the level methods did before it was
+// formatted once: separately for the console and the log file
+func infoFormattedTwice(l *Logger, msg string, v ...any) {
+	l.display(context.Background(), INFO, format(msg, v...), "")
+	l.write(l.out.now(), INFO, format(msg, v...), l.fields, "")
+}
+
+// the message is formatted once and shared by the console and the log file
+func BenchmarkInfoFormatted(b *testing.B) {
+	l := discardLogger()
+	defer l.Close()
+	b.ReportAllocs()
+	for i := range b.N {
+		l.Info("request %d handled by %s", i, "worker")
+	}
+}
+
+// the baseline for BenchmarkInfoFormatted, formatting the message twice
+func BenchmarkInfoFormattedTwice(b *testing.B) {
+	l := discardLogger()
+	defer l.Close()
+	b.ReportAllocs()
+	for i := range b.N {
+		infoFormattedTwice(l, "request %d handled by %s", i, "worker")
+	}
+}
+
+func TestFormattingOnceSavesAllocs(t *testing.T) {
+	l := discardLogger()
+	defer l.Close()
+	once := testing.AllocsPerRun(100, func() {
+		l.Info("request %d handled by %s", 1, "worker")
+	})
+	twice := testing.AllocsPerRun(100, func() {
+		infoFormattedTwice(l, "request %d handled by %s", 1, "worker")
+	})
+	// formatting allocates at least the message
+	if once > twice-1 {
+		t.Errorf("Info makes %.0f allocations, want at least one fewer than the %.0f formatting twice", once, twice)
+	}
+}
+
+// the size of the batches in BenchmarkLogBatch
+const benchBatchSize = 1000
+
+// each entry is written and flushed on its own
+func BenchmarkLogPerCall(b *testing.B) {
+	b.Setenv("LOG_DIR", b.TempDir())
+	l := NewLogger("bench", "1", WithSilentConsole())
+	defer l.Close()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		for range benchBatchSize {
+			l.Log(INFO, "batch entry")
+		}
+	}
+}
+
+// the same entries are written with one lock and one flush per batch
+func BenchmarkLogBatch(b *testing.B) {
+	b.Setenv("LOG_DIR", b.TempDir())
+	l := NewLogger("bench", "1", WithSilentConsole())
+	defer l.Close()
+	entries := make([]Entry, benchBatchSize)
+	for i := range entries {
+		entries[i] = Entry{Level: INFO, Message: "batch entry"}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		l.LogBatch(entries)
+	}
+}
@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"log"
+)
+
+// NewWriterLogger instantiates a new logger that writes its entries to w
+// instead of a log file, such as to capture them in tests or send them
+// over a connection. Entries are written in the same format as a log file,
+// starting with the header for csv. w is written to while holding the
+// logger's lock, so it doesn't need to be safe for concurrent use, and
+// isn't closed by Close. Since there's no log file, LOG_DIR and options
+// for the log file, such as WithMaxSize and WithMaxAge, don't apply, and
+// Rotate and Reopen return ErrNoFile. Messages are still displayed unless
+// WithSilentConsole is used. Like NewLogger, it exits the program if the
+// options are invalid.
+func NewWriterLogger(component string, id string, w io.Writer, opts ...Option) *Logger {
+	l, err := newLogger(component, id, opts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	o := l.out
+	o.dest = w
+	o.refs = 1
+	o.setWriter(w)
+	if o.hasHeader() {
+		err := o.writeHeader(o.buf)
+		if err == nil {
+			err = o.flush()
+		}
+		if err != nil {
+			o.fail(err)
+		}
+	}
+	if o.flushInterval > 0 {
+		o.startFlusher()
+	}
+	if o.asyncSize > 0 {
+		o.startAsync()
+	}
+	if o.limit != nil {
+		o.limit.startSummaries(l)
+	}
+	l.ref.sinks.start()
+	return l
+}
+
+// NewBufferLogger instantiates a new logger that writes its entries to an
+// in-memory buffer instead of a log file, which is mostly useful in tests.
+// See NewWriterLogger. The buffer isn't safe to read while entries are
+// being logged from other goroutines, and entries that are buffered or
+// queued, such as with WithAsync, only reach it after Flush or Close.
+func NewBufferLogger(component string, id string, opts ...Option) (*Logger, *bytes.Buffer) {
+	buf := new(bytes.Buffer)
+	return NewWriterLogger(component, id, buf, opts...), buf
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBufferLoggerCSV(t *testing.T) {
+	dir := tempLogDir(t)
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	l, buf := NewBufferLogger("buffer", "7", WithSilentConsole(),
+		WithClock(func() time.Time { return now }))
+	l.Infoln("first")
+	l.Warnln("second, with a comma")
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	want := "Time,Component,Level,Message,ID\n" +
+		"2024-03-10T12:00:00Z,buffer,INFO,first,7\n" +
+		"2024-03-10T12:00:00Z,buffer,WARN,\"second, with a comma\",7\n"
+	if got := buf.String(); got != want {
+		t.Errorf("buffer = %q, want %q", got, want)
+	}
+	// nothing is written to disk
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("log directory has %d files, want none", len(files))
+	}
+	if l.FilePath() != "" {
+		t.Errorf("FilePath() = %q, want empty", l.FilePath())
+	}
+}
+
+// entries written to another writer can be read back
+func TestWriterLoggerReadBack(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWriterLogger("buffer", "7", &buf, WithSilentConsole())
+	l.Infoln("one")
+	l.Errorln("two")
+	l.Close()
+
+	path := filepath.Join(t.TempDir(), "log.csv")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ReadEntries(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	for _, e := range entries {
+		messages = append(messages, e.Message)
+	}
+	if want := []string{"one", "two"}; !slices.Equal(messages, want) {
+		t.Errorf("read back %q, want %q", messages, want)
+	}
+}
@@ -0,0 +1,47 @@
+package logger
+
+import "testing"
+
+func TestComponentCleaned(t *testing.T) {
+	l, buf := NewBufferLogger(" billing,eu\nwest \"primary\" ", "1", WithSilentConsole())
+	l.Info("entry")
+	renamed := l.WithComponent("payments,\r\nretry")
+	renamed.Info("renamed")
+	l.Child("a,b").Info("child")
+	l.Close()
+
+	records := bufferRecords(t, buf.String())
+	want := []string{`billing_eu_west _primary_`, "payments___retry", `billing_eu_west _primary_/a_b`}
+	for i, w := range want {
+		if got := records[i][1]; got != w {
+			t.Errorf("component %d = %q, want %q", i, got, w)
+		}
+	}
+}
+
+func TestWithComponentKeepsIDAndFields(t *testing.T) {
+	sink := &memSink{}
+	l, _ := NewBufferLogger("orig", "42", WithSilentConsole(), WithSinks(sink))
+	renamed := l.WithFields(map[string]any{"region": "eu"}).WithComponent("renamed")
+	renamed.Info("entry")
+	l.Info("original")
+	l.Close()
+
+	e := sink.entries[0]
+	if e.Component != "renamed" || e.ID != "42" || e.Fields["region"] != "eu" {
+		t.Errorf("entry = %+v", e)
+	}
+	if got := sink.entries[1].Component; got != "orig" {
+		t.Errorf("original logger's component = %q, want it unchanged", got)
+	}
+}
+
+func TestComponentCleanedForDelimiter(t *testing.T) {
+	l, buf := NewBufferLogger("a\tb,c", "1", WithSilentConsole(), WithDelimiter('\t'))
+	l.Info("entry")
+	l.Close()
+	// commas are left alone when they aren't the delimiter
+	if records := bufferRecordsComma(t, buf.String(), '\t'); records[0][1] != "a_b,c" {
+		t.Errorf("component = %q, want %q", records[0][1], "a_b,c")
+	}
+}
@@ -0,0 +1,167 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// compress a log file into a .gz file alongside it, then remove the
+// original. the compressed file is written to a temporary file first so
+// an existing .gz is always complete. if one already exists the original
+// is left untouched rather than overwriting it.
+func compressFile(path string) error {
+	gzPath := path + ".gz"
+	if _, err := os.Stat(gzPath); err == nil {
+		return fmt.Errorf("compressed log file %q already exists", gzPath)
+	}
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	tmpPath := gzPath + ".tmp"
+	dst, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		dst.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := errors.Join(zw.Close(), dst.Close()); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, gzPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
+
+// compress a log file that is no longer being written to, along with
+// any of its rotated backups, in the background.
+func (o *output) compress(path string) {
+	if !o.compressOld || o.streamGzip {
+		return
+	}
+	files := []string{path}
+	for n := 1; ; n++ {
+		backup := backupPath(path, n)
+		if _, err := os.Stat(backup); err != nil {
+			break
+		}
+		files = append(files, backup)
+	}
+	o.compressing.Add(1)
+	go func() {
+		defer o.compressing.Done()
+		for _, f := range files {
+			if err := compressFile(f); err != nil && !errors.Is(err, os.ErrNotExist) {
+				log.Printf("failed to compress log file %q: %v", f, err)
+			}
+		}
+	}()
+}
+
+// WithStreamGzip writes the active log file gzip compressed, as
+// log-dd-mm-yyyy.csv.gz, to save space on constrained devices. The
+// compressed stream is flushed whenever entries are flushed to the file,
+// so entries are readable as soon as they're written, and Close, Flush,
+// Rotate, and Reopen finish or flush the stream. Each time the file is
+// opened, a new gzip member is appended to it, which gzip tools and
+// ReadEntries read as a single file.
+//
+// This is less durable than a plain file. If the program crashes or exits
+// without calling Close, the last gzip member is left unterminated: the
+// entries flushed before then can still be read, but gzip tools report
+// an unexpected end of file, and entries still buffered are lost. Entries
+// appended after the damaged member, such as by restarting the program on
+// the same day, or after a failed write, can't be read back by gzip tools
+// or ReadEntries. Flushing every entry, the default, also compresses
+// poorly, so it's best combined with WithFlushInterval or WithBufferSize.
+// WithMaxSize applies to the compressed size, and WithCompress has no
+// effect since files are already compressed. Disabled by default.
+func WithStreamGzip(enabled bool) Option {
+	return func(l *Logger) {
+		l.out.streamGzip = enabled
+	}
+}
+
+// write the header to a new or empty log file, as its own gzip
+// member if the log file is compressed.
+func (o *output) writeHeaderFile(w io.Writer) error {
+	if !o.streamGzip {
+		return o.writeHeader(w)
+	}
+	zw := gzip.NewWriter(w)
+	return errors.Join(o.writeHeader(zw), zw.Close())
+}
+
+// finish the gzip stream, if the log file is compressed, and close the
+// log file. pending entries must already be flushed.
+// must be called while holding o.mu.
+func (o *output) closeFile() error {
+	var err error
+	if o.gz != nil {
+		err = o.gz.Close()
+	}
+	// a writer given instead of a log file isn't ours to close
+	if o.file != nil {
+		if cerr := o.file.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// open a log file for reading, decompressing it if it's a .gz file.
+func openLogReader(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	} else if !strings.HasSuffix(path, ".gz") {
+		return f, nil
+	}
+	zr, err := gzip.NewReader(f)
+	if errors.Is(err, io.EOF) {
+		// empty file
+		return f, nil
+	} else if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return gzipFile{zr, f}, nil
+}
+
+// a decompressed log file. a stream that was cut off, such as the active
+// file of a logger using WithStreamGzip, is read up to where it ends.
+type gzipFile struct {
+	zr *gzip.Reader
+	f  *os.File
+}
+
+func (g gzipFile) Read(p []byte) (int, error) {
+	n, err := g.zr.Read(p)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (g gzipFile) Close() error {
+	return errors.Join(g.zr.Close(), g.f.Close())
+}
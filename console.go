@@ -0,0 +1,209 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// WithStderrLevel displays messages at or above the given level on
+// os.Stderr, and messages below it on the console output, which defaults
+// to os.Stdout. For example, WithStderrLevel(WARN) sends warnings and
+// errors to stderr and everything else to stdout. The log file still
+// receives every level. Unknown levels are ignored.
+func WithStderrLevel(level string) Option {
+	return func(l *Logger) {
+		if sev, ok := parseLevel(level); ok {
+			l.errConsole = os.Stderr
+			l.errLevel = slog.Level(sev)
+		}
+	}
+}
+
+// WithConsoleJSON controls whether messages are displayed as JSON objects
+// using slog's JSON handler, or as text using slog's text handler. This only
+// affects the console; the log file's format is set with WithFormat. By
+// default the console matches the log file's format.
+func WithConsoleJSON(enabled bool) Option {
+	return func(l *Logger) {
+		f := FormatCSV
+		if enabled {
+			f = FormatJSON
+		}
+		l.consoleFormat = &f
+	}
+}
+
+// format used to display messages
+func (l *Logger) displayFormat() Format {
+	if l.consoleFormat != nil {
+		return *l.consoleFormat
+	}
+	return l.out.format
+}
+
+// WithHandlerOptions sets options for the slog handler that displays
+// messages. They only affect the console, not the log file. Level filters
+// displayed messages on top of the logger's minimum level. ReplaceAttr is
+// called after levels are named and attributes are redacted. AddSource
+// displays the source location of each message, as WithSource does, but
+// without writing it to the log file.
+func WithHandlerOptions(opts *slog.HandlerOptions) Option {
+	return func(l *Logger) {
+		l.handlerOpts = opts
+	}
+}
+
+// build the handler used to display messages, splitting them between
+// the console and stderr and coloring levels if configured.
+func (l *Logger) consoleHandler() slog.Handler {
+	opts := l.handlerOptions()
+	h := l.displayFormat().handler(l.colorize(l.console), opts)
+	if l.errConsole == nil {
+		return h
+	}
+	return &splitHandler{
+		low:   h,
+		high:  l.displayFormat().handler(l.colorize(l.errConsole), opts),
+		level: l.errLevel,
+	}
+}
+
+// splitHandler sends records at or above level to high and the rest to low.
+type splitHandler struct {
+	low, high slog.Handler
+	level     slog.Level
+}
+
+func (h *splitHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level >= h.level {
+		return h.high.Enabled(ctx, level)
+	}
+	return h.low.Enabled(ctx, level)
+}
+
+func (h *splitHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= h.level {
+		return h.high.Handle(ctx, r)
+	}
+	return h.low.Handle(ctx, r)
+}
+
+func (h *splitHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &splitHandler{low: h.low.WithAttrs(attrs), high: h.high.WithAttrs(attrs), level: h.level}
+}
+
+func (h *splitHandler) WithGroup(name string) slog.Handler {
+	return &splitHandler{low: h.low.WithGroup(name), high: h.high.WithGroup(name), level: h.level}
+}
+
+// when to color the level in displayed messages
+type colorMode int
+
+const (
+	colorNever colorMode = iota
+	colorAuto            // only when displaying to a terminal
+	colorAlways
+)
+
+// WithColor colors the level of displayed messages when the console is a
+// terminal: DEBUG in gray, INFO in green, WARN in yellow, and ERROR and
+// FATAL in red. Colors are left out when output is redirected or the
+// NO_COLOR environment variable is set. Only the text console output is
+// colored, never the log file. See WithForceColor to always color output.
+func WithColor(enabled bool) Option {
+	return func(l *Logger) {
+		l.color = colorNever
+		if enabled {
+			l.color = colorAuto
+		}
+	}
+}
+
+// WithForceColor colors the level of displayed messages even when the
+// console isn't a terminal.
+func WithForceColor() Option {
+	return func(l *Logger) {
+		l.color = colorAlways
+	}
+}
+
+// ansi color codes for levels
+const (
+	colorReset  = "\x1b[0m"
+	colorGray   = "\x1b[90m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorRed    = "\x1b[31m"
+)
+
+// wrap w to color levels if enabled and supported by w.
+func (l *Logger) colorize(w io.Writer) io.Writer {
+	if l.displayFormat() != FormatCSV {
+		return w
+	}
+	switch l.color {
+	case colorNever:
+		return w
+	case colorAuto:
+		if _, set := os.LookupEnv("NO_COLOR"); set || !isTerminal(w) {
+			return w
+		}
+	}
+	return colorWriter{w: w}
+}
+
+// reports whether w is a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// return the color for a level
+func levelColor(level string) string {
+	sev, _ := parseLevel(level)
+	switch {
+	case sev < int(slog.LevelInfo):
+		return colorGray
+	case sev < int(slog.LevelWarn):
+		return colorGreen
+	case sev < int(slog.LevelError):
+		return colorYellow
+	}
+	return colorRed
+}
+
+// colorWriter colors the level in lines written by slog's text handler.
+type colorWriter struct {
+	w io.Writer
+}
+
+func (c colorWriter) Write(p []byte) (int, error) {
+	const key = "level="
+	i := bytes.Index(p, []byte(" "+key))
+	if i < 0 {
+		return c.w.Write(p)
+	}
+	start := i + 1 + len(key)
+	end := start + bytes.IndexByte(p[start:], ' ')
+	if end < start {
+		return c.w.Write(p)
+	}
+	level := string(p[start:end])
+	colored := make([]byte, 0, len(p)+len(colorRed)+len(colorReset))
+	colored = append(colored, p[:start]...)
+	colored = append(colored, levelColor(level)...)
+	colored = append(colored, level...)
+	colored = append(colored, colorReset...)
+	colored = append(colored, p[end:]...)
+	if _, err := c.w.Write(colored); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSilentConsole(t *testing.T) {
+	var console strings.Builder
+	l, buf := NewBufferLogger("console", "1", WithOutput(&console), WithSilentConsole())
+	l.Info("hidden")
+	l.InfoWith(map[string]any{"k": "v"}, "hidden with fields")
+	l.Close()
+	if console.Len() != 0 {
+		t.Errorf("console got %q, want nothing", console.String())
+	}
+	if !strings.Contains(buf.String(), "hidden with fields") {
+		t.Errorf("entries weren't written to the log: %q", buf.String())
+	}
+}
+
+func TestSetConsole(t *testing.T) {
+	var console strings.Builder
+	l, _ := NewBufferLogger("console", "1", WithOutput(&console))
+	defer l.Close()
+	l.SetConsole(false)
+	l.Info("hidden")
+	if console.Len() != 0 {
+		t.Errorf("console got %q after SetConsole(false)", console.String())
+	}
+	l.SetConsole(true)
+	l.Info("shown")
+	if !strings.Contains(console.String(), "shown") {
+		t.Errorf("console got %q after SetConsole(true)", console.String())
+	}
+}
+
+// run with -race to check SetConsole and SetExitCode are safe while logging
+func TestSetConsoleWhileLogging(t *testing.T) {
+	l := NewWriterLogger("console", "1", io.Discard, WithOutput(io.Discard))
+	defer l.Close()
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 500 {
+				l.Info("entry")
+			}
+		}()
+	}
+	for i := range 500 {
+		l.SetConsole(i%2 == 0)
+		l.SetExitCode(i)
+	}
+	wg.Wait()
+}
+
+func TestConsoleJSON(t *testing.T) {
+	var console strings.Builder
+	l, buf := NewBufferLogger("console", "1", WithOutput(&console), WithConsoleJSON(true))
+	l.Info("started")
+	l.WarnWith(map[string]any{"disk": "sda", "used": 95}, "disk nearly full")
+	l.Close()
+
+	lines := strings.Split(strings.TrimSpace(console.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d console lines, want 2:\n%s", len(lines), console.String())
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatalf("console line isn't JSON: %v\n%s", err, lines[1])
+	}
+	if entry["msg"] != "disk nearly full" || entry["level"] != WARN || entry["disk"] != "sda" {
+		t.Errorf("console entry = %v", entry)
+	}
+	// the log file keeps its own format
+	if !strings.HasPrefix(buf.String(), "Time,Component") {
+		t.Errorf("log file isn't csv: %q", buf.String())
+	}
+}
@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// WithContextKeys sets context keys whose values are added as fields to
+// entries logged with the Context methods, such as InfoContext. Fields are
+// named using fmt.Sprint(key). Keys without a value in the context are
+// left out of the entry.
+func WithContextKeys(keys ...any) Option {
+	return func(l *Logger) {
+		l.contextKeys = append(l.contextKeys, keys...)
+	}
+}
+
+// attribute key task IDs are displayed with
+const taskIDKey = "task_id"
+
+type taskIDContextKey struct{}
+
+// ContextWithTaskID returns a copy of ctx carrying a task ID, which
+// identifies the unit of work, such as a request or job, being done.
+// Entries logged with the Context methods, such as InfoContext, are given
+// the task ID in place of the logger's own (see WithTaskID).
+func ContextWithTaskID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, taskIDContextKey{}, id)
+}
+
+// TaskIDFromContext returns the task ID set with ContextWithTaskID, or an
+// empty string if there isn't one.
+func TaskIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(taskIDContextKey{}).(string)
+	return id
+}
+
+// WithTaskID returns a derived logger that gives every entry the task ID,
+// which identifies the unit of work, such as a request or job, that logged
+// it. Unlike the component ID, which identifies what's logging, the task
+// ID ties together entries logged for the same work, even across
+// components. It's written in the TaskID column of csv log files if
+// ColumnTaskID is included with WithColumns, as "task_id" in json log
+// files, and displayed as the task_id attribute.
+//
+// The derived logger shares l's log file, so closing either one closes
+// the file for both.
+func (l *Logger) WithTaskID(id string) *Logger {
+	child := l.derive()
+	child.taskID = id
+	return child
+}
+
+// DebugContext logs at LevelDebug with fields extracted from ctx.
+func (l *Logger) DebugContext(ctx context.Context, msg string, v ...any) {
+	if !l.enabled(DEBUG) {
+		return
+	}
+	l.logContext(ctx, DEBUG, format(msg, v...), l.caller(1))
+}
+
+// InfoContext logs at LevelInfo with fields extracted from ctx.
+func (l *Logger) InfoContext(ctx context.Context, msg string, v ...any) {
+	if !l.enabled(INFO) {
+		return
+	}
+	l.logContext(ctx, INFO, format(msg, v...), l.caller(1))
+}
+
+// WarnContext logs at LevelWarn with fields extracted from ctx.
+func (l *Logger) WarnContext(ctx context.Context, msg string, v ...any) {
+	if !l.enabled(WARN) {
+		return
+	}
+	l.logContext(ctx, WARN, format(msg, v...), l.caller(1))
+}
+
+// ErrorContext logs at LevelError with fields extracted from ctx.
+func (l *Logger) ErrorContext(ctx context.Context, msg string, v ...any) {
+	if !l.enabled(ERROR) {
+		return
+	}
+	l.logContext(ctx, ERROR, format(msg, v...), l.caller(1))
+}
+
+// display and write a message with the logger's fields plus any values
+// found in ctx for the registered context keys.
+func (l *Logger) logContext(ctx context.Context, level string, msg string, src string) {
+	defer l.out.recoverPanic()
+	ctxFields := l.contextFields(ctx)
+	taskID := l.taskID
+	if id := TaskIDFromContext(ctx); id != "" {
+		taskID = id
+	}
+	if !l.silent.Load() {
+		attrs := make([]any, 0, len(ctxFields)*2)
+		for _, k := range slices.Sorted(maps.Keys(ctxFields)) {
+			attrs = append(attrs, k, ctxFields[k])
+		}
+		if taskID != "" {
+			attrs = append(attrs, taskIDKey, taskID)
+		}
+		l.display(ctx, level, msg, src, attrs...)
+	}
+
+	fields := l.fields
+	if len(ctxFields) > 0 {
+		fields = make(map[string]any, len(l.fields)+len(ctxFields))
+		maps.Copy(fields, l.fields)
+		maps.Copy(fields, ctxFields)
+	}
+	l.writeEntry(Entry{Time: l.out.now(), Component: l.component, Level: level, Message: msg, ID: l.componentID, TaskID: taskID, Fields: fields, Source: src})
+}
+
+// extract values for the registered context keys from ctx
+func (l *Logger) contextFields(ctx context.Context) map[string]any {
+	if ctx == nil || len(l.contextKeys) == 0 {
+		return nil
+	}
+	var fields map[string]any
+	for _, key := range l.contextKeys {
+		val := ctx.Value(key)
+		if val == nil {
+			continue
+		}
+		if fields == nil {
+			fields = make(map[string]any, len(l.contextKeys))
+		}
+		fields[fmt.Sprint(key)] = val
+	}
+	return fields
+}
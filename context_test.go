@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestTaskID(t *testing.T) {
+	tempLogDir(t)
+	sink := &memSink{}
+	l := NewLogger("tasks", "7", WithSilentConsole(), WithSinks(sink),
+		WithColumns(ColumnTime, ColumnComponent, ColumnLevel, ColumnMessage, ColumnID, ColumnTaskID))
+	defer l.Close()
+
+	// loggers for concurrent jobs, each with its own task ID
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			task := l.WithTaskID(fmt.Sprintf("job-%d", i))
+			for range 10 {
+				task.Info("job %d", i)
+			}
+			// derived loggers keep the task ID
+			task.Child("step").Info("job %d", i)
+		}()
+	}
+	wg.Wait()
+	l.Infoln("no task")
+
+	entries := readLog(t, l)
+	if len(entries) != 45 {
+		t.Fatalf("got %d entries, want 45", len(entries))
+	}
+	for _, e := range entries {
+		want := ""
+		if e.Message != "no task" {
+			want = "job-" + strings.TrimPrefix(e.Message, "job ")
+		}
+		// the component ID is unaffected
+		if e.TaskID != want || e.ID != "7" {
+			t.Errorf("%q has task ID %q and ID %q, want %q and 7", e.Message, e.TaskID, e.ID, want)
+		}
+	}
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	for _, e := range sink.entries {
+		if e.Message != "no task" && e.TaskID == "" {
+			t.Errorf("sink got %q without its task ID", e.Message)
+		}
+	}
+}
+
+func TestTaskIDFromContext(t *testing.T) {
+	sink := &memSink{}
+	var console strings.Builder
+	l, _ := NewBufferLogger("tasks", "1", WithOutput(&console), WithSinks(sink))
+	task := l.WithTaskID("job-1")
+	ctx := ContextWithTaskID(context.Background(), "request-9")
+
+	task.InfoContext(ctx, "from context")
+	task.InfoContext(context.Background(), "from logger")
+	l.InfoContext(ctx, "context only")
+	l.Close()
+
+	for i, want := range []string{"request-9", "job-1", "request-9"} {
+		if got := sink.entries[i].TaskID; got != want {
+			t.Errorf("%q has task ID %q, want %q", sink.entries[i].Message, got, want)
+		}
+	}
+	if !strings.Contains(console.String(), "task_id=request-9") || !strings.Contains(console.String(), "task_id=job-1") {
+		t.Errorf("console %q doesn't show the task IDs", console.String())
+	}
+	if TaskIDFromContext(context.Background()) != "" || TaskIDFromContext(nil) != "" {
+		t.Error("TaskIDFromContext without a task ID isn't empty")
+	}
+}
+
+func TestTaskIDJSON(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("tasks", "1", WithSilentConsole(), WithFormat(FormatJSON))
+	defer l.Close()
+	l.WithTaskID("job-1").Infoln("task")
+	l.Infoln("no task")
+	entries := readLog(t, l)
+	if len(entries) != 2 || entries[0].TaskID != "job-1" || entries[1].TaskID != "" {
+		t.Errorf("entries = %+v, want only the first with task ID job-1", entries)
+	}
+}
@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// levelCounts counts entries logged at each level. counters are created
+// on first use and then only updated atomically.
+type levelCounts struct {
+	m sync.Map // level name -> *atomic.Uint64
+}
+
+// count an entry at the given level
+func (c *levelCounts) add(level string) {
+	n, ok := c.m.Load(level)
+	if !ok {
+		n, _ = c.m.LoadOrStore(level, new(atomic.Uint64))
+	}
+	n.(*atomic.Uint64).Add(1)
+}
+
+// Counts returns the number of entries logged at each level since the
+// logger was created, including entries logged by derived loggers and
+// other loggers writing to the same file. Entries dropped because of
+// the minimum level, rate limiting, or sampling aren't counted.
+func (l *Logger) Counts() map[string]uint64 {
+	counts := make(map[string]uint64)
+	l.out.counts.m.Range(func(level, n any) bool {
+		counts[level.(string)] = n.(*atomic.Uint64).Load()
+		return true
+	})
+	return counts
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"io"
+	"maps"
+	"sync"
+	"testing"
+)
+
+func TestCounts(t *testing.T) {
+	l, _ := NewBufferLogger("counts", "1", WithSilentConsole())
+	defer l.Close()
+	child := l.Child("child")
+	for range 3 {
+		l.Info("info")
+	}
+	l.Debug("below the minimum level")
+	child.Warn("warn")
+	child.Warn("warn")
+	l.Error("error")
+	l.Log("info", "lowercase")
+	l.LogBatch([]Entry{{Level: ERROR, Message: "batch"}})
+
+	want := map[string]uint64{INFO: 4, WARN: 2, ERROR: 2}
+	if got := l.Counts(); !maps.Equal(got, want) {
+		t.Errorf("Counts = %v, want %v", got, want)
+	}
+}
+
+func TestCountsConcurrent(t *testing.T) {
+	l := NewWriterLogger("counts", "1", io.Discard, WithSilentConsole())
+	defer l.Close()
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				l.Info("info")
+				l.Warn("warn")
+			}
+		}()
+	}
+	wg.Wait()
+	if got, want := l.Counts(), map[string]uint64{INFO: 8000, WARN: 8000}; !maps.Equal(got, want) {
+		t.Errorf("Counts = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"fmt"
+	"time"
+)
+
+// dedup collapses identical consecutive entries. the first entry is
+// written as usual and any repeats within the window are held back and
+// counted, then written as a single entry once the message changes or
+// the window elapses.
+type dedup struct {
+	window time.Duration
+	key    string      // identifies the last entry written
+	start  time.Time   // when the last entry was first written
+	count  int         // repeats held back
+	held   *Logger     // logger the held repeats were logged with
+	entry  Entry       // most recent held repeat
+	timer  *time.Timer // writes the held repeats once the window elapses
+}
+
+// WithDedup collapses identical consecutive entries, with the same level,
+// component, ID, message, and fields, logged within the given window.
+// The first entry is written as usual. Repeats are held back and written
+// as a single entry with the repeat count appended, such as
+// "connection refused (repeated 12 times)", when a different entry is
+// logged, the window elapses, or the logger is closed. Only the log file
+// is deduplicated, and hooks aren't called for held repeats.
+func WithDedup(window time.Duration) Option {
+	return func(l *Logger) {
+		if window > 0 {
+			l.out.dedup = &dedup{window: window}
+		}
+	}
+}
+
+// hold back e if it repeats the last entry written. returns false if
+// e is new and should be written, writing any held repeats first.
+// must be called while holding o.mu.
+func (o *output) holdRepeat(l *Logger, e Entry) bool {
+	d := o.dedup
+	key := e.Level + "\x00" + e.Component + "\x00" + e.ID + "\x00" + e.Message + "\x00" + encodeFields(e.Fields)
+	if key == d.key && e.Time.Sub(d.start) < d.window {
+		d.count++
+		o.drops.dedup.Add(1)
+		d.held, d.entry = l, e
+		if d.count == 1 {
+			d.timer = time.AfterFunc(d.window-e.Time.Sub(d.start), o.expireRepeats)
+		}
+		return true
+	}
+	o.flushRepeats()
+	d.key, d.start = key, e.Time
+	return false
+}
+
+// write any held repeats once the dedup window elapses.
+func (o *output) expireRepeats() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return
+	}
+	// start a new window so later repeats are held again
+	o.dedup.key = ""
+	if o.flushRepeats() {
+		o.autoFlush(false)
+	}
+}
+
+// write held repeats as a single entry with the repeat count appended.
+// returns true if anything was written.
+// must be called while holding o.mu.
+func (o *output) flushRepeats() bool {
+	d := o.dedup
+	if d == nil || d.count == 0 {
+		return false
+	}
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	e := d.entry
+	e.Message = fmt.Sprintf("%s (repeated %d times)", e.Message, d.count)
+	d.held.encode(e)
+	d.count, d.held, d.entry = 0, nil, Entry{}
+	return true
+}
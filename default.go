@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+// the logger set with SetDefault, if any
+var defaultLogger atomic.Pointer[Logger]
+
+// the logger used when one hasn't been set, created on first use. it only
+// displays messages if the log file can't be created, such as when the
+// working directory isn't writable, rather than exiting the program.
+var lazyDefault = sync.OnceValue(func() *Logger {
+	return NewLogger(defaultComponent(), "", WithFallbackConsole(true))
+})
+
+// name of the running binary, used as the default logger's component
+func defaultComponent() string {
+	if len(os.Args) > 0 && os.Args[0] != "" {
+		return filepath.Base(os.Args[0])
+	}
+	return "default"
+}
+
+// Default returns the package level logger used by Info, Debug, Warn,
+// Error, and Fatal. Unless one has been set with SetDefault, it's created
+// on first use with the binary's name as its component and an empty ID,
+// and only displays messages if its log file can't be created.
+func Default() *Logger {
+	if l := defaultLogger.Load(); l != nil {
+		return l
+	}
+	return lazyDefault()
+}
+
+// SetDefault sets the package level logger. The previous default
+// logger is not closed. Setting it to nil goes back to the logger
+// Default creates on first use.
+func SetDefault(l *Logger) {
+	defaultLogger.Store(l)
+}
+
+// Info logs at LevelInfo using the default logger.
+func Info(msg string, v ...any) {
+	if l := Default(); l.enabled(INFO) {
+		l.emit(INFO, format(msg, v...), l.caller(1))
+	}
+}
+
+// Debug logs at LevelDebug using the default logger.
+func Debug(msg string, v ...any) {
+	if l := Default(); l.enabled(DEBUG) {
+		l.emit(DEBUG, format(msg, v...), l.caller(1))
+	}
+}
+
+// Warn logs at LevelWarn using the default logger.
+func Warn(msg string, v ...any) {
+	if l := Default(); l.enabled(WARN) {
+		l.emit(WARN, format(msg, v...), l.caller(1))
+	}
+}
+
+// Error logs at LevelError using the default logger.
+func Error(msg string, v ...any) {
+	if l := Default(); l.enabled(ERROR) {
+		l.emit(ERROR, format(msg, v...), l.caller(1))
+	}
+}
+
+// Fatal logs at LevelFatal using the default logger, then exits the program.
+func Fatal(msg string, v ...any) {
+	l := Default()
+	l.fatal(format(msg, v...), l.caller(1))
+}
@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefault(t *testing.T) {
+	// the lazy default only displays messages when its log file can't
+	// be created, rather than exiting
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LOG_DIR", file)
+	lazy := Default()
+	if lazy == nil || lazy.FilePath() != "" {
+		t.Fatalf("Default() = %v, want a console only logger", lazy)
+	}
+
+	tempLogDir(t)
+	l := NewLogger("default", "1", WithSilentConsole())
+	defer l.Close()
+	SetDefault(l)
+	if Default() != l {
+		t.Error("Default() didn't return the logger set with SetDefault")
+	}
+	SetDefault(nil)
+	if Default() != lazy {
+		t.Error("SetDefault(nil) didn't go back to the lazy default")
+	}
+}
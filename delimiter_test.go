@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTabDelimiterRoundTrip(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("tsv", "1", WithSilentConsole(), WithDelimiter('\t'), WithExtraColumn(true))
+	defer l.Close()
+	msg := "a message with\ttabs, commas, and \"quotes\""
+	l.InfoWith(map[string]any{"path": "/tmp/a\tb"}, "%s", msg)
+	l.Flush()
+
+	path := l.FilePath()
+	if !strings.HasSuffix(path, ".tsv") {
+		t.Errorf("log file %q doesn't have the .tsv extension", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header, _, _ := strings.Cut(string(data), "\n"); header != "Time\tComponent\tLevel\tMessage\tID\tExtra" {
+		t.Errorf("header = %q, want it tab delimited", header)
+	}
+
+	entries, err := ReadEntries(path, ReadDelimiter('\t'))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Message != msg || entries[0].Fields["path"] != "/tmp/a\tb" {
+		t.Errorf("read back %+v", entries)
+	}
+}
+
+func TestCustomDelimiter(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("semicolon", "1", WithSilentConsole(), WithDelimiter(';'))
+	defer l.Close()
+	l.Info("one; two")
+	l.Flush()
+	if !strings.HasSuffix(l.FilePath(), ".csv") {
+		t.Errorf("log file %q doesn't have the .csv extension", l.FilePath())
+	}
+	entries, err := ReadEntries(l.FilePath(), ReadDelimiter(';'))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Message != "one; two" || entries[0].Component != "semicolon" {
+		t.Errorf("read back %+v", entries)
+	}
+}
+
+func TestInvalidDelimiterIgnored(t *testing.T) {
+	l, buf := NewBufferLogger("delim", "1", WithSilentConsole(), WithDelimiter('"'))
+	l.Close()
+	if !strings.HasPrefix(buf.String(), "Time,Component") {
+		t.Errorf("header = %q, want the default delimiter", buf.String())
+	}
+}
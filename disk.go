@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"log"
+	"time"
+)
+
+// how often free space on the log filesystem is checked
+const diskCheckInterval = time.Minute
+
+// return the free space on the filesystem holding a directory. a variable
+// so tests can simulate a filling disk.
+var diskFree = statDiskFree
+
+// WithLowDiskThreshold writes a WARN entry when free space on the
+// filesystem holding the log file falls below the given number of bytes,
+// so a disk filling up doesn't go unnoticed until writes start failing.
+// Free space is checked at most once a minute, when an entry is written.
+// The warning is written once, and again only after free space has gone
+// back above the threshold and fallen below it again. If WithMaxAge is
+// set, old log files are removed as soon as space runs low rather than at
+// the next rollover. Free space is checked with statfs on Linux, macOS,
+// and the BSDs, and GetDiskFreeSpaceEx on Windows; on other platforms this
+// has no effect. 0 disables the check, which is the default.
+func WithLowDiskThreshold(bytes uint64) Option {
+	return func(l *Logger) {
+		l.out.lowDisk = bytes
+	}
+}
+
+// check free space on the log filesystem if it's been long enough since
+// the last check, warning once if it's low.
+// must be called while holding l.out.mu.
+func (l *Logger) checkDisk() {
+	o := l.out
+	if o.lowDisk == 0 || o.file == nil {
+		return
+	}
+	now := o.now().In(o.loc)
+	if now.Before(o.nextDiskCheck) {
+		return
+	}
+	o.nextDiskCheck = now.Add(diskCheckInterval)
+	free, err := diskFree(o.dir)
+	if err != nil {
+		// not supported, or the log directory is gone. there's no need to
+		// keep trying.
+		log.Printf("failed to check free space in %q: %v", o.dir, err)
+		o.lowDisk = 0
+		return
+	}
+	if free >= o.lowDisk {
+		o.lowDiskWarned = false
+		return
+	}
+	if o.lowDiskWarned {
+		return
+	}
+	o.lowDiskWarned = true
+	// the warning can't be written while holding the lock, so it's left
+	// for warnLowDisk to write once the entry that found space low is done
+	o.lowDiskWarning.Store(&Entry{
+		Time:      now,
+		Component: l.component,
+		Level:     WARN,
+		Message:   format("low disk space: %d bytes free in %s, below the threshold of %d bytes", free, o.dir, o.lowDisk),
+		ID:        l.componentID,
+	})
+	o.cleanup(now)
+}
+
+// write the low disk space warning found by checkDisk, if there is one,
+// like any other entry so it's counted and passed to hooks and sinks.
+// must not be called while holding l.out.mu.
+func (l *Logger) warnLowDisk() {
+	if l.out.lowDiskWarning.Load() == nil {
+		return
+	}
+	e := l.out.lowDiskWarning.Swap(nil)
+	if e == nil {
+		return
+	}
+	l.out.counts.add(e.Level)
+	l.writeNow(*e, true)
+}
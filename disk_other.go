@@ -0,0 +1,10 @@
+//go:build !(darwin || dragonfly || freebsd || linux || windows)
+
+package logger
+
+import "errors"
+
+// checking free space isn't supported on this platform
+func statDiskFree(dir string) (uint64, error) {
+	return 0, errors.ErrUnsupported
+}
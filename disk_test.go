@@ -0,0 +1,195 @@
+package logger
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// a filesystem whose free space the test controls
+type fakeDisk struct {
+	mu     sync.Mutex
+	free   uint64
+	err    error
+	checks int
+}
+
+// replace diskFree with d for the test
+func (d *fakeDisk) install(t *testing.T) {
+	old := diskFree
+	diskFree = func(string) (uint64, error) {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.checks++
+		return d.free, d.err
+	}
+	t.Cleanup(func() { diskFree = old })
+}
+
+func (d *fakeDisk) set(free uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.free = free
+}
+
+// the messages of the low disk warnings in entries
+func lowDiskWarnings(entries []Entry) []string {
+	var warnings []string
+	for _, e := range entries {
+		if e.Level == WARN && strings.HasPrefix(e.Message, "low disk space") {
+			warnings = append(warnings, e.Message)
+		}
+	}
+	return warnings
+}
+
+func TestLowDiskWarning(t *testing.T) {
+	disk := &fakeDisk{free: 10_000}
+	disk.install(t)
+	tempLogDir(t)
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	l := NewLogger("disk", "1", WithSilentConsole(), WithLowDiskThreshold(1000),
+		WithClock(func() time.Time { return now }))
+	defer l.Close()
+
+	l.Infoln("plenty of space")
+	disk.set(500)
+	// not checked again until a minute has passed
+	l.Infoln("still within the minute")
+	now = now.Add(time.Minute)
+	l.Infoln("low")
+	now = now.Add(time.Minute)
+	l.Infoln("still low")
+
+	entries := readLog(t, l)
+	warnings := lowDiskWarnings(entries)
+	if len(warnings) != 1 {
+		t.Fatalf("got %d warnings, want 1: %q", len(warnings), warnings)
+	}
+	if !strings.Contains(warnings[0], "500 bytes free") {
+		t.Errorf("warning = %q, want it to report the free space", warnings[0])
+	}
+	// the warning follows the entry that found space low
+	var messages []string
+	for _, e := range entries {
+		messages = append(messages, e.Message)
+	}
+	if i := slices.Index(messages, "low"); i < 0 || i+1 >= len(messages) || messages[i+1] != warnings[0] {
+		t.Errorf("entries = %q, want the warning right after %q", messages, "low")
+	}
+	if disk.checks != 3 {
+		t.Errorf("free space checked %d times, want 3", disk.checks)
+	}
+}
+
+func TestLowDiskWarningRearms(t *testing.T) {
+	disk := &fakeDisk{free: 500}
+	disk.install(t)
+	tempLogDir(t)
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	l := NewLogger("disk", "1", WithSilentConsole(), WithLowDiskThreshold(1000),
+		WithClock(func() time.Time { return now }))
+	defer l.Close()
+
+	for _, free := range []uint64{500, 2000, 500, 400} {
+		disk.set(free)
+		l.Infoln("entry")
+		now = now.Add(time.Minute)
+	}
+	// warned when it first fell below, and again after recovering
+	if warnings := lowDiskWarnings(readLog(t, l)); len(warnings) != 2 {
+		t.Errorf("got %d warnings, want 2: %q", len(warnings), warnings)
+	}
+}
+
+// the warning is written like any other entry, so it's counted and
+// passed to hooks and sinks
+func TestLowDiskWarningHooksAndSinks(t *testing.T) {
+	disk := &fakeDisk{free: 500}
+	disk.install(t)
+	tempLogDir(t)
+	sink := &memSink{}
+	l := NewLogger("disk", "1", WithSilentConsole(), WithLowDiskThreshold(1000), WithSinks(sink))
+	var hooked []Entry
+	l.OnLevel(WARN, func(e Entry) { hooked = append(hooked, e) })
+	l.Infoln("entry")
+	l.Close()
+
+	if n := len(lowDiskWarnings(sink.entries)); n != 1 {
+		t.Errorf("sink got %d warnings, want 1", n)
+	}
+	if n := len(lowDiskWarnings(hooked)); n != 1 {
+		t.Errorf("hook got %d warnings, want 1", n)
+	}
+	if n := l.Counts()[WARN]; n != 1 {
+		t.Errorf("counted %d WARN entries, want 1", n)
+	}
+}
+
+// old log files are removed as soon as space runs low rather than at
+// the next rollover
+func TestLowDiskRemovesOldLogs(t *testing.T) {
+	disk := &fakeDisk{free: 10_000}
+	disk.install(t)
+	dir := tempLogDir(t)
+	touch(t, dir, "log-09-03-2024.csv")
+	now := time.Date(2024, 3, 10, 1, 0, 0, 0, time.UTC)
+	l := NewLogger("disk", "1", WithSilentConsole(), WithLowDiskThreshold(1000), WithMaxAge(12*time.Hour),
+		WithClock(func() time.Time { return now }))
+	defer l.Close()
+	l.Infoln("plenty of space")
+	if got := listDir(t, dir); !slices.Contains(got, "log-09-03-2024.csv") {
+		t.Fatalf("files = %v, want log-09-03-2024.csv kept while it's within the max age", got)
+	}
+
+	// old enough to remove, but there's no rollover until midnight
+	now = now.Add(12 * time.Hour)
+	disk.set(500)
+	l.Infoln("low")
+	if got := listDir(t, dir); slices.Contains(got, "log-09-03-2024.csv") {
+		t.Errorf("files = %v, want log-09-03-2024.csv removed when space ran low", got)
+	}
+}
+
+func TestLowDiskCheckFails(t *testing.T) {
+	disk := &fakeDisk{err: errors.ErrUnsupported}
+	disk.install(t)
+	tempLogDir(t)
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	l := NewLogger("disk", "1", WithSilentConsole(), WithLowDiskThreshold(1000),
+		WithClock(func() time.Time { return now }))
+	defer l.Close()
+	for range 3 {
+		l.Infoln("entry")
+		now = now.Add(time.Minute)
+	}
+	// there's no point checking again after it fails
+	if disk.checks != 1 {
+		t.Errorf("free space checked %d times, want 1", disk.checks)
+	}
+}
+
+func TestLowDiskDisabled(t *testing.T) {
+	disk := &fakeDisk{free: 0}
+	disk.install(t)
+	tempLogDir(t)
+	l := NewLogger("disk", "1", WithSilentConsole())
+	defer l.Close()
+	l.Infoln("entry")
+	if disk.checks != 0 {
+		t.Errorf("free space checked %d times without a threshold", disk.checks)
+	}
+}
+
+func TestStatDiskFree(t *testing.T) {
+	free, err := statDiskFree(t.TempDir())
+	if errors.Is(err, errors.ErrUnsupported) {
+		t.Skip("checking free space isn't supported on this platform")
+	}
+	if err != nil || free == 0 {
+		t.Errorf("statDiskFree = %d, %v, want the free space", free, err)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build darwin || dragonfly || freebsd || linux
+
+package logger
+
+import "syscall"
+
+// free space in bytes available to unprivileged users on the filesystem
+// holding dir
+func statDiskFree(dir string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return 0, err
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), nil
+}
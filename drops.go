@@ -0,0 +1,54 @@
+package logger
+
+import "sync/atomic"
+
+// Reasons entries are dropped, used as the keys of DropStats.
+const (
+	DropRateLimit    = "rate_limit"    // beyond the limit set with WithRateLimit
+	DropSampling     = "sampling"      // not sampled with WithSampling
+	DropDedup        = "dedup"         // repeats collapsed by WithDedup
+	DropThrottle     = "throttle"      // repeats within the ttl set with WithThrottle
+	DropAsync        = "async"         // the WithAsync buffer was full with AsyncDrop
+	DropWriteTimeout = "write_timeout" // writes abandoned by WithWriteTimeout
+	DropSink         = "sink"          // a sink fell behind or failed to write
+)
+
+// counts of entries dropped for each reason. they're only atomics so
+// they're cheap to update while logging.
+type dropCounts struct {
+	rateLimit    atomic.Uint64
+	sampling     atomic.Uint64
+	dedup        atomic.Uint64
+	throttle     atomic.Uint64
+	async        atomic.Uint64
+	writeTimeout atomic.Uint64
+}
+
+// DropStats returns the number of entries dropped for each reason since
+// the logger was created, keyed by the Drop constants, such as
+// DropRateLimit, including reasons nothing has been dropped for. Like
+// Counts, it includes entries logged by derived loggers and other loggers
+// writing to the same file. DropWriteTimeout counts abandoned writes,
+// each of which may have held several entries, and DropSink counts
+// entries each of the logger's own sinks dropped, including those
+// reported by a sink's own Dropped method, such as HTTPSink's. Entries below the minimum level
+// aren't counted.
+func (l *Logger) DropStats() map[string]uint64 {
+	d := &l.out.drops
+	var sinks uint64
+	for _, r := range l.ref.sinks {
+		sinks += r.dropped.Load()
+		if s, ok := r.sink.(interface{ Dropped() uint64 }); ok {
+			sinks += s.Dropped()
+		}
+	}
+	return map[string]uint64{
+		DropRateLimit:    d.rateLimit.Load(),
+		DropSampling:     d.sampling.Load(),
+		DropDedup:        d.dedup.Load(),
+		DropThrottle:     d.throttle.Load(),
+		DropAsync:        d.async.Load(),
+		DropWriteTimeout: d.writeTimeout.Load(),
+		DropSink:         sinks,
+	}
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDropStatsAsync(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("drops", "1", WithSilentConsole(), WithAsync(4), WithAsyncPolicy(AsyncDrop))
+	defer l.Close()
+
+	// hold the output so the writer can't drain the buffer while it's
+	// flooded
+	l.out.mu.Lock()
+	for range 100 {
+		l.Infoln("flood")
+	}
+	l.out.mu.Unlock()
+
+	dropped := l.DropStats()[DropAsync]
+	if dropped == 0 {
+		t.Fatal("DropStats()[DropAsync] = 0, want entries dropped")
+	}
+	entries := readLog(t, l)
+	var written uint64
+	var summary bool
+	for _, e := range entries {
+		switch {
+		case e.Message == "flood":
+			written++
+		case e.Level == WARN && strings.Contains(e.Message, "async buffer full"):
+			summary = true
+		}
+	}
+	if written+dropped != 100 {
+		t.Errorf("wrote %d and dropped %d entries, want 100 in all", written, dropped)
+	}
+	if !summary {
+		t.Error("no summary of the dropped entries was written")
+	}
+}
+
+func TestDropStatsLimits(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	clock := WithClock(func() time.Time { return now })
+
+	limited, _ := NewBufferLogger("drops", "1", WithSilentConsole(), clock, WithRateLimit(5))
+	defer limited.Close()
+	sampled, _ := NewBufferLogger("drops", "2", WithSilentConsole(), clock, WithSampling(4))
+	defer sampled.Close()
+	deduped, _ := NewBufferLogger("drops", "3", WithSilentConsole(), clock, WithDedup(time.Minute))
+	defer deduped.Close()
+	for range 20 {
+		limited.Infoln("entry")
+		sampled.Infoln("entry")
+		deduped.Infoln("entry")
+	}
+
+	for _, c := range []struct {
+		l      *Logger
+		reason string
+		want   uint64
+	}{
+		{limited, DropRateLimit, 15},
+		{sampled, DropSampling, 15},
+		{deduped, DropDedup, 19},
+	} {
+		stats := c.l.DropStats()
+		for reason, n := range stats {
+			want := uint64(0)
+			if reason == c.reason {
+				want = c.want
+			}
+			if n != want {
+				t.Errorf("%s logger: DropStats()[%s] = %d, want %d", c.reason, reason, n, want)
+			}
+		}
+	}
+}
+
+func TestDropStatsReasons(t *testing.T) {
+	l, _ := NewBufferLogger("drops", "1", WithSilentConsole())
+	defer l.Close()
+	want := []string{DropRateLimit, DropSampling, DropDedup, DropThrottle, DropAsync, DropWriteTimeout, DropSink}
+	stats := l.DropStats()
+	if len(stats) != len(want) {
+		t.Errorf("DropStats has %d reasons, want %d", len(stats), len(want))
+	}
+	for _, reason := range want {
+		if n, ok := stats[reason]; !ok || n != 0 {
+			t.Errorf("DropStats()[%s] = %d, %t, want 0 for a new logger", reason, n, ok)
+		}
+	}
+}
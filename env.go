@@ -0,0 +1,31 @@
+package logger
+
+import (
+	"os"
+	"strings"
+)
+
+// apply configuration from the environment. called before options are
+// applied, so options take precedence. invalid values are ignored.
+//
+//   - LOG_FORMAT: "csv" or "json", the on-disk format (see WithFormat)
+//   - LOG_CONSOLE: "text", "json", or "off", how messages are displayed
+//     (see WithConsoleJSON and WithSilentConsole)
+//
+// LOG_LEVEL is read by levelFromEnv.
+func (l *Logger) applyEnv() {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("LOG_FORMAT"))) {
+	case "csv":
+		l.out.format = FormatCSV
+	case "json":
+		l.out.format = FormatJSON
+	}
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("LOG_CONSOLE"))) {
+	case "text":
+		WithConsoleJSON(false)(l)
+	case "json":
+		WithConsoleJSON(true)(l)
+	case "off":
+		l.silent.Store(true)
+	}
+}
@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLogLevelEnv(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "warn")
+	l, _ := NewBufferLogger("env", "1", WithSilentConsole())
+	defer l.Close()
+	if got := l.Level(); got != WARN {
+		t.Errorf("Level = %s, want WARN from LOG_LEVEL", got)
+	}
+
+	t.Setenv("LOG_LEVEL", "nonsense")
+	l2, _ := NewBufferLogger("env", "1", WithSilentConsole())
+	defer l2.Close()
+	if got := l2.Level(); got != INFO {
+		t.Errorf("Level with an invalid LOG_LEVEL = %s, want INFO", got)
+	}
+}
+
+func TestLogFormatEnv(t *testing.T) {
+	tempLogDir(t)
+	t.Setenv("LOG_FORMAT", "json")
+	l := NewLogger("env", "1", WithSilentConsole())
+	defer l.Close()
+	if !strings.HasSuffix(l.FilePath(), ".jsonl") {
+		t.Errorf("log file %q isn't json lines", l.FilePath())
+	}
+
+	// options override the environment
+	o := NewLogger("env", "1", WithSilentConsole(), WithFormat(FormatCSV))
+	defer o.Close()
+	if !strings.HasSuffix(o.FilePath(), ".csv") {
+		t.Errorf("with WithFormat(FormatCSV), log file is %q", o.FilePath())
+	}
+}
+
+func TestLogConsoleEnv(t *testing.T) {
+	t.Setenv("LOG_CONSOLE", "json")
+	var console strings.Builder
+	l, _ := NewBufferLogger("env", "1", WithOutput(&console))
+	l.Info("json")
+	l.Close()
+	var v map[string]any
+	if err := json.Unmarshal([]byte(console.String()), &v); err != nil {
+		t.Errorf("console isn't JSON with LOG_CONSOLE=json: %q", console.String())
+	}
+
+	t.Setenv("LOG_CONSOLE", "off")
+	console.Reset()
+	l, _ = NewBufferLogger("env", "1", WithOutput(&console))
+	l.Info("hidden")
+	l.Close()
+	if console.Len() != 0 {
+		t.Errorf("console got %q with LOG_CONSOLE=off", console.String())
+	}
+}
+
+func TestEnvPrecedence(t *testing.T) {
+	t.Setenv("LOG_CONSOLE", "json")
+	var console strings.Builder
+	l, _ := NewBufferLogger("env", "1", WithOutput(&console), WithConsoleJSON(false))
+	l.Info("text")
+	l.Close()
+	if strings.HasPrefix(console.String(), "{") || !strings.Contains(console.String(), "msg=text") {
+		t.Errorf("WithConsoleJSON(false) didn't override LOG_CONSOLE: %q", console.String())
+	}
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorErr logs the message at LevelError along with err. The error is
+// recorded in the entry's fields: its message as "error", the messages of
+// the errors it wraps as "error_chain", and its "%+v" form as "stack" if
+// that adds anything, such as the stack trace recorded by some error
+// packages. Redaction rules apply to each of these fields, including every
+// message in the chain. If err is nil, ErrorErr logs the message like
+// Error.
+func (l *Logger) ErrorErr(err error, msg string, v ...any) {
+	if !l.enabled(ERROR) {
+		return
+	}
+	msg, src := format(msg, v...), l.caller(1)
+	if err == nil {
+		l.emit(ERROR, msg, src)
+		return
+	}
+	l.emitWith(ERROR, errorFields(err), msg, src)
+}
+
+// fields describing an error
+func errorFields(err error) map[string]any {
+	fields := map[string]any{"error": err.Error()}
+	if chain := errorChain(err); len(chain) > 1 {
+		fields["error_chain"] = chain
+	}
+	if detail := fmt.Sprintf("%+v", err); detail != err.Error() {
+		fields["stack"] = detail
+	}
+	return fields
+}
+
+// messages of err and every error it wraps, depth first
+func errorChain(err error) []string {
+	var chain []string
+	var walk func(error)
+	walk = func(err error) {
+		for err != nil {
+			chain = append(chain, err.Error())
+			if joined, ok := err.(interface{ Unwrap() []error }); ok {
+				for _, e := range joined.Unwrap() {
+					walk(e)
+				}
+				return
+			}
+			err = errors.Unwrap(err)
+		}
+	}
+	walk(err)
+	return chain
+}
@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestErrorErrRecordsChain(t *testing.T) {
+	sink := &memSink{}
+	l, _ := NewBufferLogger("errors", "1", WithSilentConsole(), WithSinks(sink))
+	base := errors.New("connection refused")
+	l.ErrorErr(fmt.Errorf("query users: %w", base), "request failed")
+	l.Close()
+
+	fields := sink.entries[0].Fields
+	if fields["error"] != "query users: connection refused" {
+		t.Errorf("error = %v", fields["error"])
+	}
+	want := []string{"query users: connection refused", "connection refused"}
+	if chain, _ := fields["error_chain"].([]string); !slices.Equal(chain, want) {
+		t.Errorf("error_chain = %#v, want %#v", fields["error_chain"], want)
+	}
+}
+
+func TestErrorErrRedactsChain(t *testing.T) {
+	sink := &memSink{}
+	l, _ := NewBufferLogger("errors", "1", WithSilentConsole(), WithSinks(sink),
+		WithRedactPattern(cardPattern, "****"))
+	base := errors.New("card 4111-1111-1111-1111 declined")
+	l.ErrorErr(fmt.Errorf("charge: %w", base), "payment failed")
+	l.Close()
+
+	fields := sink.entries[0].Fields
+	if fields["error"] != "charge: card **** declined" {
+		t.Errorf("error = %v", fields["error"])
+	}
+	want := []string{"charge: card **** declined", "card **** declined"}
+	if chain, _ := fields["error_chain"].([]string); !slices.Equal(chain, want) {
+		t.Errorf("error_chain = %#v, want %#v", fields["error_chain"], want)
+	}
+}
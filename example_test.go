@@ -0,0 +1,23 @@
+package logger_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/null-create/logger"
+)
+
+// Change a running service's minimum level over HTTP, such as to turn on
+// debug logging while investigating a problem. SetLevel is safe to call
+// while other goroutines are logging.
+func ExampleLogger_SetLevel() {
+	log := logger.NewLogger("api", "1")
+	defer log.Close()
+
+	http.HandleFunc("/debug/level", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			log.SetLevel(r.FormValue("level"))
+		}
+		fmt.Fprintln(w, log.Level())
+	})
+}
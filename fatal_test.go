@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestFatal runs itself in a subprocess, which logs a fatal entry and
+// exits, then checks the exit code and that the entry reached the file.
+func TestFatal(t *testing.T) {
+	if os.Getenv("LOGGER_TEST_FATAL") == "1" {
+		l := NewLogger("fatal", "1", WithSilentConsole(), WithBufferSize(64*1024))
+		l.SetExitCode(3)
+		l.Fatal("shutting down: %s", "disk gone")
+		return
+	}
+	dir := t.TempDir()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFatal$")
+	cmd.Env = append(os.Environ(), "LOGGER_TEST_FATAL=1", "LOG_DIR="+dir)
+	err := cmd.Run()
+	var exit *exec.ExitError
+	if !errors.As(err, &exit) || exit.ExitCode() != 3 {
+		t.Fatalf("subprocess exited with %v, want exit code 3", err)
+	}
+
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Fatalf("got %d log files, want 1", len(files))
+	}
+	entries, err := ReadEntries(filepath.Join(dir, files[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Level != FATAL || entries[0].Message != "shutting down: disk gone" {
+		t.Errorf("entries = %+v, want the fatal entry", entries)
+	}
+}
@@ -0,0 +1,259 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+)
+
+// WithFields returns a derived logger that attaches the given fields to
+// every entry. Fields are merged with any already attached to l, with
+// the new values taking precedence. On the console the fields are
+// displayed as attributes, and in the log file they are written as a
+// JSON object in an additional column.
+//
+// The derived logger shares l's log file, so closing either one closes
+// the file for both.
+func (l *Logger) WithFields(fields map[string]any) *Logger {
+	child := l.derive()
+	child.fields = make(map[string]any, len(l.fields)+len(fields))
+	maps.Copy(child.fields, l.fields)
+	maps.Copy(child.fields, fields)
+
+	attrs := make([]any, 0, len(fields)*2)
+	for _, k := range slices.Sorted(maps.Keys(fields)) {
+		attrs = append(attrs, k, fields[k])
+	}
+	child.log = l.log.With(attrs...)
+	return child
+}
+
+// InfoWith logs at LevelInfo with fields attached to this entry only,
+// on top of any attached to the logger, and displays the message. Unlike
+// WithFields, the fields don't carry over to later entries. Fields given
+// here take precedence over the logger's fields with the same key.
+func (l *Logger) InfoWith(fields map[string]any, msg string, v ...any) {
+	if !l.enabled(INFO) {
+		return
+	}
+	l.emitWith(INFO, fields, format(msg, v...), l.caller(1))
+}
+
+// DebugWith logs at LevelDebug with fields attached to this entry only.
+// See InfoWith.
+func (l *Logger) DebugWith(fields map[string]any, msg string, v ...any) {
+	if !l.enabled(DEBUG) {
+		return
+	}
+	l.emitWith(DEBUG, fields, format(msg, v...), l.caller(1))
+}
+
+// WarnWith logs at LevelWarn with fields attached to this entry only.
+// See InfoWith.
+func (l *Logger) WarnWith(fields map[string]any, msg string, v ...any) {
+	if !l.enabled(WARN) {
+		return
+	}
+	l.emitWith(WARN, fields, format(msg, v...), l.caller(1))
+}
+
+// ErrorWith logs at LevelError with fields attached to this entry only.
+// See InfoWith.
+func (l *Logger) ErrorWith(fields map[string]any, msg string, v ...any) {
+	if !l.enabled(ERROR) {
+		return
+	}
+	l.emitWith(ERROR, fields, format(msg, v...), l.caller(1))
+}
+
+// display the message with the given fields as attributes and write it
+// with the fields merged over the logger's.
+func (l *Logger) emitWith(level string, fields map[string]any, msg string, src string) {
+	defer l.out.recoverPanic()
+	if len(fields) == 0 {
+		l.emit(level, msg, src)
+		return
+	}
+	merged := make(map[string]any, len(l.fields)+len(fields))
+	maps.Copy(merged, l.fields)
+	maps.Copy(merged, fields)
+
+	if !l.silent.Load() {
+		attrs := make([]any, 0, len(fields)*2)
+		for _, k := range slices.Sorted(maps.Keys(fields)) {
+			attrs = append(attrs, k, fields[k])
+		}
+		l.display(context.Background(), level, msg, src, attrs...)
+	}
+	l.write(l.out.now(), level, msg, merged, src)
+}
+
+// encode fields as a JSON object. map keys are sorted
+// by encoding/json so the output is stable.
+func encodeFields(fields map[string]any) string {
+	b, err := json.Marshal(jsonFields(fields))
+	if err != nil {
+		return fmt.Sprintf(`{"error":%q}`, err.Error())
+	}
+	return string(b)
+}
+
+// convert field values that encoding/json would encode poorly, or fail
+// to encode at all, so a single bad value doesn't lose the other fields:
+// errors are written as their message, durations as strings such as
+// "1.5s", and values JSON can't represent, such as NaN or channels, as
+// formatted by fmt, including in nested maps and slices. times are written
+// in RFC 3339 format by encoding/json.
+func jsonFields(fields map[string]any) map[string]any {
+	if len(fields) == 0 {
+		return fields
+	}
+	converted := make(map[string]any, len(fields))
+	for k, v := range fields {
+		converted[k] = jsonValue(v)
+	}
+	return converted
+}
+
+func jsonValue(v any) any {
+	switch v := v.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return v
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Sprint(v)
+		}
+		return v
+	case float32:
+		return jsonValue(float64(v))
+	case json.Marshaler, encoding.TextMarshaler:
+		return v
+	case error:
+		return v.Error()
+	case time.Duration:
+		return v.String()
+	case map[string]any:
+		return jsonFields(v)
+	case []any:
+		converted := make([]any, len(v))
+		for i, e := range v {
+			converted[i] = jsonValue(e)
+		}
+		return converted
+	}
+	if _, err := json.Marshal(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return v
+}
+
+// decode fields written by encodeFields. numbers are decoded as int64 if
+// they're whole and fit, and as float64 otherwise, and times and
+// durations are restored, including in nested objects and arrays.
+func decodeFields(data []byte) (map[string]any, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var fields map[string]any
+	if err := d.Decode(&fields); err != nil {
+		return nil, err
+	}
+	return typedValues(fields).(map[string]any), nil
+}
+
+// replace the json.Numbers decoded with UseNumber with int64 or float64,
+// and the strings jsonValue writes for times and durations with time.Time
+// and time.Duration
+func typedValues(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case string:
+		return typedString(v)
+	case map[string]any:
+		for k, e := range v {
+			v[k] = typedValues(e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = typedValues(e)
+		}
+	}
+	return v
+}
+
+// restore a time or duration from a string. only strings in exactly the
+// form they're written in are converted, so other strings that happen to
+// parse, such as "0" or "1h", stay strings.
+func typedString(s string) any {
+	if len(s) >= len("2006-01-02T15:04:05Z") && s[4] == '-' && s[10] == 'T' {
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil && t.Format(time.RFC3339Nano) == s {
+			return t
+		}
+	}
+	if strings.HasSuffix(s, "s") || strings.HasSuffix(s, "m") || strings.HasSuffix(s, "h") {
+		if d, err := time.ParseDuration(s); err == nil && d.String() == s {
+			return d
+		}
+	}
+	return s
+}
+
+// Child returns a derived logger for a sub-component of l, with its
+// component set to "parent/subcomponent". The child keeps l's ID and
+// fields, and shares l's log file, so its entries are serialized with
+// l's and no additional file handles are opened. Closing l or any of
+// its children closes the file for all of them, after which their
+// entries are no longer written to it.
+func (l *Logger) Child(subcomponent string) *Logger {
+	child := l.derive()
+	child.component = l.component + "/" + l.out.cleanComponent(subcomponent)
+	return child
+}
+
+// WithComponent returns a derived logger with its component renamed, keeping
+// l's ID and fields. Like Child, it shares l's log file. The name is cleaned
+// the same way as the component passed to NewLogger.
+func (l *Logger) WithComponent(name string) *Logger {
+	child := l.derive()
+	child.component = l.out.cleanComponent(name)
+	return child
+}
+
+// copy l for a derived logger. the level, console setting, and exit code
+// are copied rather than shared, so the child's can be changed without
+// affecting l's.
+func (l *Logger) derive() *Logger {
+	child := *l
+	child.level = new(atomic.Int64)
+	child.level.Store(l.level.Load())
+	child.silent = new(atomic.Bool)
+	child.silent.Store(l.silent.Load())
+	child.exitCode = new(atomic.Int64)
+	child.exitCode.Store(l.exitCode.Load())
+	return &child
+}
+
+// clean a component name so it stays readable in the log file. control
+// characters such as newlines, quotes, and the field delimiter are
+// replaced with underscores, and leading and trailing spaces are removed.
+func (o *output) cleanComponent(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' || r == o.comma {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+}
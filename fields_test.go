@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFieldsEncodeSorted(t *testing.T) {
+	fields := map[string]any{"ok": true, "count": 5, "name": "db", "ratio": 0.5}
+	want := `{"count":5,"name":"db","ok":true,"ratio":0.5}`
+	for range 10 {
+		if got := encodeFields(fields); got != want {
+			t.Fatalf("encodeFields = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestFieldsRoundTrip(t *testing.T) {
+	ts := time.Date(2024, 3, 10, 12, 30, 0, 500, time.FixedZone("", 2*3600))
+	fields := map[string]any{
+		"count":   5,
+		"ok":      true,
+		"ratio":   0.25,
+		"name":    "db",
+		"ts":      ts,
+		"elapsed": 1500 * time.Millisecond,
+		"nested":  map[string]any{"at": ts, "retries": 3},
+		"list":    []any{"a", 2, 90 * time.Minute},
+		// strings that parse as durations but aren't in their written form
+		"zero":   "0",
+		"window": "1h",
+		"empty":  "",
+	}
+	want := map[string]any{
+		"count":   int64(5),
+		"ok":      true,
+		"ratio":   0.25,
+		"name":    "db",
+		"ts":      ts,
+		"elapsed": 1500 * time.Millisecond,
+		"nested":  map[string]any{"at": ts, "retries": int64(3)},
+		"list":    []any{"a", int64(2), 90 * time.Minute},
+		"zero":    "0",
+		"window":  "1h",
+		"empty":   "",
+	}
+
+	got, err := decodeFields([]byte(encodeFields(fields)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalFields(got, want) {
+		t.Errorf("decoded %#v\nwant %#v", got, want)
+	}
+
+	tempLogDir(t)
+	l := NewLogger("fields", "1", WithSilentConsole(), WithExtraColumn(true))
+	defer l.Close()
+	l.InfoWith(fields, "typed")
+	entries := readLog(t, l)
+	if len(entries) != 1 || !equalFields(entries[0].Fields, want) {
+		t.Errorf("read back %#v\nwant %#v", entries, want)
+	}
+}
+
+// compare fields, comparing times with Equal since their locations differ
+// once read back
+func equalFields(got, want any) bool {
+	switch w := want.(type) {
+	case time.Time:
+		g, ok := got.(time.Time)
+		return ok && g.Equal(w)
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok || len(g) != len(w) {
+			return false
+		}
+		for k, v := range w {
+			if !equalFields(g[k], v) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		g, ok := got.([]any)
+		if !ok || len(g) != len(w) {
+			return false
+		}
+		for i := range w {
+			if !equalFields(g[i], w[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(got, want)
+}
@@ -0,0 +1,51 @@
+package logger
+
+import "log"
+
+// WithFileLock controls whether an advisory lock is held on the log file
+// while entries are being written to it, so rows written by different
+// processes logging to the same file don't interleave. The lock is taken
+// for each entry and released once it's been written, so other processes
+// are never kept waiting longer than a single write. With WithAsync, the
+// background writer writes each entry as soon as it's locked rather than
+// once the queue is empty. It can't be combined with WithBufferSize,
+// WithFlushInterval, or WithAutoFlush(false), which would hold the lock
+// until the buffer is flushed; creating a logger with both fails.
+//
+// Locks are taken with flock on Unix and LockFileEx on Windows, and are
+// only respected by other processes that also lock the file. On platforms
+// without file locking, or if the lock can't be taken, a warning is
+// logged and entries are written without locking. Disabled by default.
+func WithFileLock(enabled bool) Option {
+	return func(l *Logger) {
+		l.out.fileLock = enabled
+	}
+}
+
+// take the file lock before entries are buffered, if enabled and not
+// already held. if locking fails, it's disabled rather than retried on
+// every entry.
+// must be called while holding o.mu.
+func (o *output) lock() {
+	if !o.fileLock || o.locked || o.file == nil {
+		return
+	}
+	if err := lockFile(o.file); err != nil {
+		log.Printf("failed to lock log file %q, writing without locking: %v", o.path, err)
+		o.fileLock = false
+		return
+	}
+	o.locked = true
+}
+
+// release the file lock, if held, once buffered entries have been written.
+// must be called while holding o.mu.
+func (o *output) unlock() {
+	if !o.locked {
+		return
+	}
+	if err := unlockFile(o.file); err != nil {
+		log.Printf("failed to unlock log file %q: %v", o.path, err)
+	}
+	o.locked = false
+}
@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFileLockRejectsBufferedWrites(t *testing.T) {
+	tempLogDir(t)
+	for name, opt := range map[string]Option{
+		"WithBufferSize":    WithBufferSize(4096),
+		"WithFlushInterval": WithFlushInterval(time.Second),
+		"WithAutoFlush":     WithAutoFlush(false),
+	} {
+		if _, err := NewLoggerE("lock", "1", WithFileLock(true), opt); err == nil {
+			t.Errorf("WithFileLock with %s succeeded, want an error", name)
+		}
+	}
+}
+
+func TestFileLockReleasedAfterEachEntry(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("lock", "1", WithSilentConsole(), WithFileLock(true), WithFlushLevel(""))
+	defer l.Close()
+	for range 10 {
+		l.Info("entry")
+		l.out.mu.Lock()
+		locked := l.out.locked
+		l.out.mu.Unlock()
+		if locked {
+			t.Fatal("file lock still held after the entry was written")
+		}
+	}
+	if n := len(readLog(t, l)); n != 10 {
+		t.Errorf("got %d entries, want 10", n)
+	}
+}
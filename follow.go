@@ -0,0 +1,253 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// how often Follow checks the log file for new entries
+const followInterval = 250 * time.Millisecond
+
+// Follow calls fn for each entry appended to a log file from now on, like
+// tail -f, until stop is called. The file is polled for new entries, which
+// are parsed the same way as ReadEntries, and fn is called on a background
+// goroutine. Partially written entries are held back until they're
+// complete.
+//
+// If the file is replaced, such as when it's rotated with WithMaxSize or
+// by logrotate, the rest of the old file is read and the new one is
+// followed from its start. If it's truncated, it's followed from its start
+// again. Follow always follows the given path, so it doesn't move on to
+// the next day's file when the logger rolls over. Compressed files can't
+// be followed. Entries that can't be parsed are skipped and reported with
+// the standard log package.
+func Follow(path string, fn func(Entry), opts ...ReadOption) (stop func(), err error) {
+	if strings.HasSuffix(path, ".gz") {
+		return nil, fmt.Errorf("can't follow compressed log file %q", path)
+	}
+	f := &follower{
+		path: path,
+		cfg:  newReadConfig(opts),
+		json: isJSONLog(path),
+		fn:   fn,
+	}
+	if err := f.open(false); err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(followInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				f.close()
+				return
+			case <-ticker.C:
+				f.poll()
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		<-stopped
+	}, nil
+}
+
+// follower reads entries appended to a log file
+type follower struct {
+	path    string
+	cfg     readConfig
+	json    bool // whether the file holds json lines
+	fn      func(Entry)
+	file    *os.File    // open handle to the file, nil if it couldn't be reopened
+	info    os.FileInfo // the file's info when it was opened, to detect replacement
+	offset  int64       // bytes read from the file
+	pending []byte      // bytes read that don't make up a complete entry yet
+	header  []string    // header of a csv file, nil until it's been read
+	cols    columnIndex // positions of the columns in the header
+}
+
+// open the file, reading the header of csv files, and position it at its
+// start or end
+func (f *follower) open(fromStart bool) error {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.info = file, info
+	f.offset, f.pending, f.header = 0, nil, nil
+	if !f.json {
+		// the header is read here so the file can be followed from its end
+		r := csv.NewReader(file)
+		r.Comma = f.cfg.comma
+		r.Comment = '#' // the schema version line
+		r.FieldsPerRecord = -1
+		header, err := r.Read()
+		if err == nil {
+			if err := f.setHeader(header); err != nil {
+				f.close()
+				return err
+			}
+			f.offset = r.InputOffset()
+		} else if !errors.Is(err, io.EOF) {
+			f.close()
+			return fmt.Errorf("failed to read log file header: %w", err)
+		}
+	}
+	if !fromStart {
+		f.offset = info.Size()
+	}
+	if _, err := file.Seek(f.offset, io.SeekStart); err != nil {
+		f.close()
+		return err
+	}
+	return nil
+}
+
+// locate the columns in a csv header
+func (f *follower) setHeader(header []string) error {
+	cols, err := newColumnIndex(header, f.cfg)
+	if err != nil {
+		return err
+	}
+	f.header, f.cols = slices.Clone(header), cols
+	return nil
+}
+
+func (f *follower) close() {
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+}
+
+// read new entries, then check whether the file was replaced or truncated
+func (f *follower) poll() {
+	if f.file == nil {
+		// the file was replaced but the new one didn't exist yet
+		if err := f.open(true); err != nil {
+			return
+		}
+	}
+	f.read()
+
+	info, err := os.Stat(f.path)
+	switch {
+	case err != nil:
+		// removed, keep waiting on the old file until it's replaced
+	case !os.SameFile(info, f.info):
+		// entries may have been written to the old file since it was read
+		f.read()
+		f.close()
+		if err := f.open(true); err == nil {
+			f.read()
+		}
+	case info.Size() < f.offset:
+		// truncated in place, such as by logrotate's copytruncate
+		if _, err := f.file.Seek(0, io.SeekStart); err == nil {
+			f.offset, f.pending = 0, nil
+			f.read()
+		}
+	}
+}
+
+// read to the end of the file, calling fn for each complete entry
+func (f *follower) read() {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := f.file.Read(buf)
+		f.offset += int64(n)
+		f.pending = append(f.pending, buf[:n]...)
+		if n == 0 || err != nil {
+			break
+		}
+	}
+	for {
+		end := f.recordEnd()
+		if end < 0 {
+			return
+		}
+		record := f.pending[:end+1]
+		f.pending = f.pending[end+1:]
+		if err := f.parse(record); err != nil {
+			log.Printf("failed to follow log file %q: %v", f.path, err)
+		}
+	}
+}
+
+// index of the newline ending the first complete entry in pending, or -1.
+// csv entries may contain newlines inside quoted fields, so those are
+// skipped.
+func (f *follower) recordEnd() int {
+	if f.json {
+		return bytes.IndexByte(f.pending, '\n')
+	}
+	quoted := false
+	for i, b := range f.pending {
+		switch b {
+		case '"':
+			quoted = !quoted
+		case '\n':
+			if !quoted {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parse a complete entry and pass it to fn
+func (f *follower) parse(record []byte) error {
+	if len(bytes.TrimSpace(record)) == 0 || bytes.HasPrefix(record, []byte(schemaLinePrefix)) {
+		return nil
+	}
+	if f.json {
+		e, err := parseJSONEntry(record, f.cfg.timeFormat)
+		if err != nil {
+			return err
+		}
+		f.fn(e)
+		return nil
+	}
+
+	r := csv.NewReader(bytes.NewReader(record))
+	r.Comma = f.cfg.comma
+	r.FieldsPerRecord = -1
+	fields, err := r.Read()
+	if err != nil {
+		return err
+	}
+	switch {
+	case f.header == nil:
+		// a new file, so this is its header
+		return f.setHeader(fields)
+	case slices.Equal(fields, f.header):
+		// the header of a file truncated in place and started again
+		return nil
+	}
+	e, err := f.cols.parse(fields)
+	if err != nil {
+		return err
+	}
+	f.fn(e)
+	return nil
+}
@@ -0,0 +1,145 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// records the messages of the entries it's called with
+type followed struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (f *followed) add(e Entry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.messages = append(f.messages, e.Message)
+}
+
+// wait for the given messages to have been followed
+func (f *followed) wait(t *testing.T, want ...string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		f.mu.Lock()
+		got := slices.Clone(f.messages)
+		f.mu.Unlock()
+		if slices.Equal(got, want) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("followed %q, want %q", got, want)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestFollow(t *testing.T) {
+	for _, format := range []Format{FormatCSV, FormatJSON} {
+		tempLogDir(t)
+		l := NewLogger("follow", "1", WithSilentConsole(), WithFormat(format))
+		l.Infoln("before following")
+		l.Flush()
+
+		f := &followed{}
+		stop, err := Follow(l.FilePath(), f.add)
+		if err != nil {
+			t.Fatalf("Follow: %v", err)
+		}
+		// entries already in the file aren't followed
+		l.Infoln("first")
+		l.Infoln("second, with\na line break")
+		f.wait(t, "first", "second, with\na line break")
+
+		stop()
+		stop()
+		l.Infoln("after stopping")
+		time.Sleep(2 * followInterval)
+		f.wait(t, "first", "second, with\na line break")
+		l.Close()
+	}
+}
+
+// a partially written entry is held back until it's complete
+func TestFollowPartialEntry(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "partial.csv")
+	if err := os.WriteFile(path, []byte("Time,Component,Level,Message,ID\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f := &followed{}
+	stop, err := Follow(path, f.add)
+	if err != nil {
+		t.Fatalf("Follow: %v", err)
+	}
+	defer stop()
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	file.WriteString(`2024-03-10T12:00:00Z,follow,INFO,"split`)
+	time.Sleep(2 * followInterval)
+	f.wait(t)
+	file.WriteString("\nacross writes\",1\n")
+	f.wait(t, "split\nacross writes")
+}
+
+func TestFollowRotated(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("follow", "1", WithSilentConsole())
+	defer l.Close()
+	f := &followed{}
+	stop, err := Follow(l.FilePath(), f.add)
+	if err != nil {
+		t.Fatalf("Follow: %v", err)
+	}
+	defer stop()
+
+	l.Infoln("old file")
+	if err := l.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	l.Infoln("new file")
+	f.wait(t, "old file", "new file")
+}
+
+// a file truncated in place, such as by logrotate's copytruncate, is
+// followed from its start again
+func TestFollowTruncated(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "truncated.csv")
+	header := "Time,Component,Level,Message,ID\n"
+	long := header + "2024-03-10T12:00:00Z,follow,INFO,a long entry written before truncation,1\n"
+	if err := os.WriteFile(path, []byte(long), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f := &followed{}
+	stop, err := Follow(path, f.add)
+	if err != nil {
+		t.Fatalf("Follow: %v", err)
+	}
+	defer stop()
+
+	short := header + "2024-03-10T12:00:01Z,follow,INFO,again,1\n"
+	if err := os.WriteFile(path, []byte(short), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f.wait(t, "again")
+}
+
+func TestFollowErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Follow(filepath.Join(dir, "missing.csv"), func(Entry) {}); err == nil {
+		t.Error("Follow of a missing file succeeded")
+	}
+	if _, err := Follow(filepath.Join(dir, "log.csv.gz"), func(Entry) {}); err == nil {
+		t.Error("Follow of a compressed file succeeded")
+	}
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"io"
+	"log/slog"
+)
+
+// Format is the on-disk format of the log file.
+type Format int
+
+const (
+	// FormatCSV writes entries as rows in a .csv file with a header. This is the default.
+	FormatCSV Format = iota
+	// FormatJSON writes entries as newline delimited JSON objects in a .jsonl file.
+	FormatJSON
+)
+
+// json representation of a single log entry
+type jsonEntry struct {
+	Time      string         `json:"time"`
+	Component string         `json:"component"`
+	Level     string         `json:"level"`
+	Message   string         `json:"message"`
+	ID        string         `json:"id"`
+	TaskID    string         `json:"task_id,omitempty"`
+	Host      string         `json:"host,omitempty"`
+	Seq       uint64         `json:"seq,omitempty"`
+	Source    string         `json:"source,omitempty"`
+	Fields    map[string]any `json:"fields,omitempty"`
+}
+
+// file extension used for the format
+func (f Format) ext() string {
+	if f == FormatJSON {
+		return ".jsonl"
+	}
+	return ".csv"
+}
+
+// slog handler matching the format, used to display messages
+func (f Format) handler(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	if f == FormatJSON {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+// WithFormatter writes entries to the log file in a custom format, such as
+// logfmt or a fixed width layout, instead of the logger's Format. Each
+// entry is written as the bytes returned by format followed by a newline.
+// Entries are passed with their time in the logger's time zone, and their
+// Source is only set if WithSource is enabled. The log file has no header,
+// uses the .log extension, and can't be read back with ReadEntries. Options
+// specific to csv files, such as WithColumns and WithSanitizeCSV, don't
+// apply, so the formatter should escape anything it needs to.
+func WithFormatter(format func(Entry) []byte) Option {
+	return func(l *Logger) {
+		l.out.formatter = format
+	}
+}
+
+// formats an entry as a line of a log file, without the newline
+type rowFormatter func(Entry) []byte
@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// counts how many times it's formatted
+type countingStringer struct{ calls int }
+
+func (s *countingStringer) String() string {
+	s.calls++
+	return "value"
+}
+
+func TestMessageFormattedOnce(t *testing.T) {
+	var console strings.Builder
+	l, buf := NewBufferLogger("format", "1", WithOutput(&console))
+	s := &countingStringer{}
+	l.Info("got %s", s)
+	l.Close()
+	if s.calls != 1 {
+		t.Errorf("message formatted %d times, want once", s.calls)
+	}
+	if !strings.Contains(console.String(), "got value") || !strings.Contains(buf.String(), "got value") {
+		t.Errorf("console %q and log %q should both have the message", console.String(), buf.String())
+	}
+}
+
+func TestMessageWithoutArgsIsVerbatim(t *testing.T) {
+	l, buf := NewBufferLogger("format", "1", WithSilentConsole())
+	msg := "disk at 95% used"
+	// called through a variable since vet would flag the literal %
+	logError := l.Error
+	logError(msg)
+	l.Info("%d%% done, %s", 50, "halfway")
+	l.Close()
+	records := bufferRecords(t, buf.String())
+	if got := records[0][3]; got != msg {
+		t.Errorf("message without args written as %q, want %q", got, msg)
+	}
+	if got := records[1][3]; got != "50% done, halfway" {
+		t.Errorf("formatted message written as %q", got)
+	}
+}
+
+// format an entry as logfmt
+func logfmt(e Entry) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "time=%s level=%s component=%s msg=%s id=%s",
+		e.Time.Format(time.RFC3339), e.Level, e.Component, strconv.Quote(e.Message), e.ID)
+	for _, k := range slices.Sorted(maps.Keys(e.Fields)) {
+		fmt.Fprintf(&b, " %s=%v", k, e.Fields[k])
+	}
+	return []byte(b.String())
+}
+
+func TestFormatter(t *testing.T) {
+	dir := tempLogDir(t)
+	loc := time.FixedZone("UTC+2", 2*3600)
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	l := NewLogger("logfmt", "1", WithSilentConsole(), WithFormatter(logfmt), WithTimeZone(loc),
+		WithClock(func() time.Time { return now }))
+	l.Info("started")
+	l.WithFields(map[string]any{"attempt": 2, "user": "ada"}).Warn("slow, retrying")
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	path := filepath.Join(dir, "log-10-03-2024.log")
+	if l.FilePath() != path {
+		t.Errorf("FilePath = %s, want %s", l.FilePath(), path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// no header, and the time is in the logger's time zone
+	want := `time=2024-03-10T14:00:00+02:00 level=INFO component=logfmt msg="started" id=1
+time=2024-03-10T14:00:00+02:00 level=WARN component=logfmt msg="slow, retrying" id=1 attempt=2 user=ada
+`
+	if string(data) != want {
+		t.Errorf("log file =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestFormatterAppends(t *testing.T) {
+	tempLogDir(t)
+	for _, msg := range []string{"first", "second"} {
+		l := NewLogger("logfmt", "1", WithSilentConsole(), WithFormatter(func(e Entry) []byte {
+			return []byte(e.Message)
+		}))
+		l.Infoln(msg)
+		l.Close()
+		if msg == "second" {
+			data, err := os.ReadFile(l.FilePath())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != "first\nsecond\n" {
+				t.Errorf("log file = %q, want both lines and no header", data)
+			}
+		}
+	}
+}
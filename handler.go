@@ -0,0 +1,163 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"runtime"
+)
+
+// CSVHandler is a slog.Handler that writes records to a Logger's log file.
+// Records are written using the standard columns, with the record's
+// attributes and any attributes added with WithAttrs written as a JSON
+// object in an additional column. Groups are written as nested objects.
+//
+// CSVHandler does not display records; use it with slog.New to route
+// slog output into the log file:
+//
+//	log := slog.New(logger.NewCSVHandler(l))
+type CSVHandler struct {
+	l      *Logger
+	attrs  map[string]any // attributes added with WithAttrs
+	groups []string       // open groups, outermost first
+}
+
+// NewCSVHandler returns a handler that writes to l's log file.
+// The handler uses l's component, ID, fields, and minimum level.
+func NewCSVHandler(l *Logger) *CSVHandler {
+	return &CSVHandler{l: l, attrs: map[string]any{}}
+}
+
+// map a slog level to the package's level names
+func levelFromSlog(level slog.Level) string {
+	switch {
+	case level < slog.LevelInfo:
+		return DEBUG
+	case level < slog.LevelWarn:
+		return INFO
+	case level < slog.LevelError:
+		return WARN
+	case level < slogLevelFatal:
+		return ERROR
+	default:
+		return FATAL
+	}
+}
+
+// Enabled reports whether the logger's minimum level lets the level through.
+// Rate limiting and sampling aren't applied until the record is handled,
+// since slog may call Enabled without handling a record.
+func (h *CSVHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.l.levelEnabled(levelFromSlog(level))
+}
+
+// Handle writes the record to the log file, unless it's dropped by rate
+// limiting or sampling.
+func (h *CSVHandler) Handle(_ context.Context, r slog.Record) error {
+	if h.l.out.limit != nil && !h.l.allow() {
+		return nil
+	}
+	fields := make(map[string]any, len(h.l.fields)+len(h.attrs)+r.NumAttrs())
+	maps.Copy(fields, h.l.fields)
+	maps.Copy(fields, h.attrs)
+	dst := h.group(fields)
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(dst, a)
+		return true
+	})
+	if len(fields) == 0 {
+		fields = nil
+	}
+	t := r.Time
+	if t.IsZero() {
+		t = h.l.out.now()
+	}
+	var src string
+	if h.l.out.addSource && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		src = formatSource(frame.File, frame.Line)
+	}
+	h.l.write(t, levelFromSlog(r.Level), r.Message, fields, src)
+	return nil
+}
+
+// WithAttrs returns a handler that includes the given attributes in every record.
+func (h *CSVHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := h.clone()
+	dst := h2.group(h2.attrs)
+	for _, a := range attrs {
+		addAttr(dst, a)
+	}
+	return h2
+}
+
+// WithGroup returns a handler that nests subsequent attributes under name.
+func (h *CSVHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := h.clone()
+	h2.groups = append(h2.groups, name)
+	return h2
+}
+
+// deep copy the handler's attributes so derived handlers don't share maps
+func (h *CSVHandler) clone() *CSVHandler {
+	return &CSVHandler{
+		l:      h.l,
+		attrs:  cloneAttrs(h.attrs),
+		groups: append([]string(nil), h.groups...),
+	}
+}
+
+// return the map that attributes should be added to given the open groups,
+// creating nested maps as needed.
+func (h *CSVHandler) group(m map[string]any) map[string]any {
+	for _, g := range h.groups {
+		sub, ok := m[g].(map[string]any)
+		if !ok {
+			sub = map[string]any{}
+		} else {
+			sub = maps.Clone(sub)
+		}
+		m[g] = sub
+		m = sub
+	}
+	return m
+}
+
+func cloneAttrs(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		if sub, ok := v.(map[string]any); ok {
+			v = cloneAttrs(sub)
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// add an attribute to m, expanding groups into nested maps
+func addAttr(m map[string]any, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() != slog.KindGroup {
+		if a.Key != "" {
+			m[a.Key] = v.Any()
+		}
+		return
+	}
+	attrs := v.Group()
+	if len(attrs) == 0 {
+		return
+	}
+	// inline groups with empty keys
+	dst := m
+	if a.Key != "" {
+		sub := map[string]any{}
+		m[a.Key] = sub
+		dst = sub
+	}
+	for _, ga := range attrs {
+		addAttr(dst, ga)
+	}
+}
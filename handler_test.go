@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// checking whether a level is enabled doesn't use up the rate limit
+func TestCSVHandlerEnabledDoesNotLimit(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	l, _ := NewBufferLogger("slog", "1", WithSilentConsole(), WithRateLimit(2),
+		WithClock(func() time.Time { return now }))
+	defer l.Close()
+	h := NewCSVHandler(l)
+	for range 10 {
+		if !h.Enabled(context.Background(), slog.LevelInfo) {
+			t.Fatal("Enabled(INFO) = false, want true")
+		}
+	}
+	if h.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("Enabled(DEBUG) = true with an INFO minimum level")
+	}
+	log := slog.New(h)
+	for range 5 {
+		log.Info("entry")
+	}
+	if n := l.DropStats()[DropRateLimit]; n != 3 {
+		t.Errorf("dropped %d entries, want 3", n)
+	}
+	if n := l.Counts()[INFO]; n != 2 {
+		t.Errorf("wrote %d entries, want 2", n)
+	}
+}
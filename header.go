@@ -0,0 +1,172 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrHeaderMismatch is returned when an existing csv log file's header
+// doesn't match the columns the logger would write.
+var ErrHeaderMismatch = errors.New("log file header doesn't match the expected columns")
+
+// WithHeaderMigration controls what happens when an existing csv log file
+// has a different header than the logger expects, such as one written
+// before a column was added. When enabled, the existing file is moved to
+// a numbered backup, like with WithMaxSize, and a new file is started.
+// Otherwise creating the logger fails with ErrHeaderMismatch so rows with
+// different columns aren't mixed in one file. Disabled by default.
+func WithHeaderMigration(enabled bool) Option {
+	return func(l *Logger) {
+		l.out.migrateHeader = enabled
+	}
+}
+
+// SchemaVersion is the version of the csv log file layout written by
+// this package, recorded in log files when WithSchemaVersion is enabled.
+const SchemaVersion = 1
+
+// prefix of the line recording the schema version
+const schemaLinePrefix = "# logger-schema="
+
+// WithSchemaVersion writes a line such as "# logger-schema=1" before the
+// header of csv log files, so files written with different layouts can be
+// told apart. ReadEntries skips the line, and ReadSchemaVersion returns the
+// version it records. Disabled by default so log files are plain csv.
+func WithSchemaVersion(enabled bool) Option {
+	return func(l *Logger) {
+		l.out.schemaVersion = enabled
+	}
+}
+
+// ReadSchemaVersion returns the schema version recorded in a csv log file,
+// or 0 if the file doesn't record one.
+func ReadSchemaVersion(path string) (int, error) {
+	f, err := openLogReader(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return readSchemaLine(bufio.NewReader(f))
+}
+
+// read the schema version line at the start of a csv log file, if there
+// is one, leaving r at the header. returns 0 if there isn't one.
+func readSchemaLine(r *bufio.Reader) (int, error) {
+	b, err := r.Peek(1)
+	if errors.Is(err, io.EOF) || (err == nil && b[0] != '#') {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	line, err := r.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+	line = strings.TrimSpace(line)
+	version, err := strconv.Atoi(strings.TrimPrefix(line, schemaLinePrefix))
+	if err != nil || !strings.HasPrefix(line, schemaLinePrefix) {
+		return 0, fmt.Errorf("invalid schema version line %q", line)
+	}
+	return version, nil
+}
+
+// the schema version expected in log files written by this output, 0 if none
+func (o *output) expectedVersion() int {
+	if o.schemaVersion {
+		return SchemaVersion
+	}
+	return 0
+}
+
+// write the schema version line, if enabled, and header row of a csv log file
+func (o *output) writeHeader(w io.Writer) error {
+	if o.schemaVersion {
+		if _, err := fmt.Fprintf(w, "%s%d\n", schemaLinePrefix, SchemaVersion); err != nil {
+			return err
+		}
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = o.comma
+	cw.Write(o.header())
+	cw.Flush()
+	return cw.Error()
+}
+
+// read the schema version and header row of a csv log file. returns a
+// nil header if the file is empty.
+func readHeader(path string, comma rune) ([]string, int, error) {
+	f, err := openLogReader(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	version, err := readSchemaLine(br)
+	if err != nil {
+		return nil, 0, err
+	}
+	r := csv.NewReader(br)
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, version, nil
+	}
+	return header, version, err
+}
+
+// whether log files start with a header row. only csv files written
+// without a custom formatter have one.
+func (o *output) hasHeader() bool {
+	return o.format == FormatCSV && o.formatter == nil
+}
+
+// check that an existing csv log file has the expected header before
+// appending to it. empty files are given the header, and files with
+// a different header are either moved aside or rejected.
+func (o *output) checkHeader(logFile string) error {
+	if !o.hasHeader() {
+		return nil
+	}
+	header, version, err := readHeader(logFile, o.comma)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read header of log file %q: %w", logFile, err)
+	}
+
+	expected := o.header()
+	switch {
+	case header == nil && version == 0:
+		return o.writeHeaderTo(logFile)
+	case slices.Equal(header, expected) && version == o.expectedVersion():
+		return nil
+	case !o.migrateHeader && version != o.expectedVersion():
+		return fmt.Errorf("%w: %q has schema version %d, expected %d", ErrHeaderMismatch, logFile, version, o.expectedVersion())
+	case !o.migrateHeader:
+		return fmt.Errorf("%w: %q has columns %v, expected %v", ErrHeaderMismatch, logFile, header, expected)
+	}
+	if err := shiftBackups(logFile, o.maxBackups); err != nil {
+		return fmt.Errorf("failed to move log file %q with old header: %w", logFile, err)
+	}
+	if err := os.Rename(logFile, backupPath(logFile, 1)); err != nil {
+		return fmt.Errorf("failed to move log file %q with old header: %w", logFile, err)
+	}
+	return nil
+}
+
+// write the header to an empty log file
+func (o *output) writeHeaderTo(logFile string) error {
+	f, err := os.OpenFile(logFile, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	return errors.Join(o.writeHeaderFile(f), f.Close())
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// seed today's shared log file in dir with data, returning its path
+func seedLogFile(t *testing.T, dir string, now time.Time, data string) string {
+	t.Helper()
+	path := logFilePath(dir, "", now, ".csv")
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestHeaderMismatchDetected(t *testing.T) {
+	dir := tempLogDir(t)
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	old := "Time,Level,Message\n2024-03-10T01:00:00Z,INFO,old row\n"
+	path := seedLogFile(t, dir, now, old)
+
+	_, err := NewLoggerE("header", "1", WithSilentConsole(), WithClock(func() time.Time { return now }))
+	if !errors.Is(err, ErrHeaderMismatch) {
+		t.Fatalf("NewLoggerE = %v, want ErrHeaderMismatch", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != old {
+		t.Errorf("file was modified: %q", data)
+	}
+}
+
+func TestHeaderMigration(t *testing.T) {
+	dir := tempLogDir(t)
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	old := "Time,Level,Message\n2024-03-10T01:00:00Z,INFO,old row\n"
+	path := seedLogFile(t, dir, now, old)
+
+	l := NewLogger("header", "1", WithSilentConsole(), WithHeaderMigration(true), WithClock(func() time.Time { return now }))
+	defer l.Close()
+	l.Info("new row")
+	entries := readLog(t, l)
+	if len(entries) != 1 || entries[0].Message != "new row" {
+		t.Errorf("new file has %+v", entries)
+	}
+	if data, _ := os.ReadFile(path); !strings.HasPrefix(string(data), "Time,Component,Level,Message,ID\n") {
+		t.Errorf("new file doesn't start with the header: %q", data)
+	}
+	backups, _ := filepath.Glob(filepath.Join(dir, "log-10-03-2024.*.csv"))
+	if len(backups) != 1 {
+		t.Fatalf("got backups %q, want one", backups)
+	}
+	if data, _ := os.ReadFile(backups[0]); string(data) != old {
+		t.Errorf("backup has %q, want the old file", data)
+	}
+}
+
+func TestMatchingHeaderAppends(t *testing.T) {
+	dir := tempLogDir(t)
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	seedLogFile(t, dir, now, "Time,Component,Level,Message,ID\n2024-03-10T01:00:00Z,header,INFO,old row,1\n")
+
+	l := NewLogger("header", "1", WithSilentConsole(), WithClock(func() time.Time { return now }))
+	defer l.Close()
+	l.Info("new row")
+	var msgs []string
+	for _, e := range readLog(t, l) {
+		msgs = append(msgs, e.Message)
+	}
+	if !slices.Equal(msgs, []string{"old row", "new row"}) {
+		t.Errorf("messages = %q", msgs)
+	}
+}
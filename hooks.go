@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"log"
+	"runtime/debug"
+)
+
+// a function called for entries at or above a level
+type hook struct {
+	severity int
+	level    string // set instead of severity for unregistered levels
+	fn       func(Entry)
+}
+
+// OnLevel registers fn to be called with each entry written at or above
+// the given level, such as to send an alert for every ERROR and FATAL
+// entry. For unregistered levels, fn is only called for entries at exactly
+// that level. Hooks are shared with derived loggers and other loggers
+// writing to the same log file.
+//
+// Hooks are called after the entry has been written, on the goroutine that
+// logged it and outside of the logger's lock, so they don't block other
+// loggers but do delay the logging call that triggered them. With
+// WithAsync, they're called on a background goroutine instead, after the
+// async writer has written the entry, and Flush and Close wait for them
+// to finish. Hooks may log, but shouldn't call Flush or Close. Hooks are
+// called in the order they were registered, each one finishing before the
+// next is called. A hook that panics is recovered so it can't crash the
+// program or prevent the remaining hooks from running.
+func (l *Logger) OnLevel(level string, fn func(Entry)) {
+	h := hook{fn: fn}
+	if sev, ok := parseLevel(level); ok {
+		h.severity = sev
+	} else {
+		h.level = level
+	}
+	l.out.hookMu.Lock()
+	defer l.out.hookMu.Unlock()
+	l.out.hooks = append(l.out.hooks, h)
+}
+
+// reports whether the hook should be called for the entry
+func (h hook) matches(e Entry) bool {
+	if h.level != "" {
+		return e.Level == h.level
+	}
+	sev, ok := parseLevel(e.Level)
+	return ok && sev >= h.severity
+}
+
+// call the hooks matching the entry. must not be called while holding o.mu.
+func (o *output) runHooks(e Entry) {
+	o.hookMu.RLock()
+	hooks := o.hooks
+	o.hookMu.RUnlock()
+	for _, h := range hooks {
+		if h.matches(e) {
+			callHook(h.fn, e)
+		}
+	}
+}
+
+// call a hook, recovering from any panic
+func callHook(fn func(Entry), e Entry) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("log hook panicked: %v\n%s", r, debug.Stack())
+		}
+	}()
+	fn(e)
+}
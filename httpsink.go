@@ -0,0 +1,238 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrSinkClosed is returned when writing to a sink that has been closed.
+var ErrSinkClosed = errors.New("sink is closed")
+
+// HTTPSink is a Sink that sends batches of entries to a log collector
+// as JSON arrays in POST requests.
+type HTTPSink struct {
+	url           string
+	client        *http.Client
+	batchSize     int
+	flushInterval time.Duration
+	retries       int
+	backoff       time.Duration
+	header        http.Header
+	encode        func([]Entry) ([]byte, error) // encodes a batch as a request body
+
+	mu      sync.Mutex // guards batch and closed
+	batch   []Entry
+	closed  bool
+	sendMu  sync.Mutex    // serializes requests so batches arrive in order
+	dropped atomic.Uint64 // entries dropped after failing to send them
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+// HTTPOption configures an HTTPSink.
+type HTTPOption func(*HTTPSink)
+
+// HTTPBatchSize sets the maximum number of entries sent in one request.
+// Defaults to 100.
+func HTTPBatchSize(n int) HTTPOption {
+	return func(s *HTTPSink) {
+		if n > 0 {
+			s.batchSize = n
+		}
+	}
+}
+
+// HTTPFlushInterval sets how often a partial batch is sent. Defaults to
+// 5 seconds.
+func HTTPFlushInterval(d time.Duration) HTTPOption {
+	return func(s *HTTPSink) {
+		if d > 0 {
+			s.flushInterval = d
+		}
+	}
+}
+
+// HTTPRetries sets how many times a failed request is retried, waiting
+// twice as long between each attempt, starting with backoff. Defaults to
+// 3 retries starting at 500ms.
+func HTTPRetries(retries int, backoff time.Duration) HTTPOption {
+	return func(s *HTTPSink) {
+		s.retries = max(retries, 0)
+		s.backoff = backoff
+	}
+}
+
+// HTTPHeader adds a header to every request, such as for authentication.
+func HTTPHeader(key, value string) HTTPOption {
+	return func(s *HTTPSink) {
+		s.header.Add(key, value)
+	}
+}
+
+// HTTPClient sets the client used to send requests. Defaults to a client
+// with a 10 second timeout.
+func HTTPClient(c *http.Client) HTTPOption {
+	return func(s *HTTPSink) {
+		if c != nil {
+			s.client = c
+		}
+	}
+}
+
+// NewHTTPSink returns a sink that sends entries to url as JSON arrays,
+// in the same form as FormatJSON log files. Entries are sent once a batch
+// fills up or the flush interval passes, whichever comes first. Requests
+// that fail are retried with backoff; if they keep failing the batch is
+// dropped and counted. Entries are still written to the log file, so none
+// are lost entirely.
+func NewHTTPSink(url string, opts ...HTTPOption) *HTTPSink {
+	s := &HTTPSink{
+		url:           url,
+		client:        &http.Client{Timeout: 10 * time.Second},
+		batchSize:     100,
+		flushInterval: 5 * time.Second,
+		retries:       3,
+		backoff:       500 * time.Millisecond,
+		header:        make(http.Header),
+		encode:        encodeJSONBatch,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	go s.flusher()
+	return s
+}
+
+// WriteEntry adds an entry to the current batch, sending it if it's full.
+// Entries written after Close are dropped and ErrSinkClosed is returned.
+func (s *HTTPSink) WriteEntry(e Entry) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		s.dropped.Add(1)
+		return ErrSinkClosed
+	}
+	s.batch = append(s.batch, e)
+	if len(s.batch) < s.batchSize {
+		s.mu.Unlock()
+		return nil
+	}
+	batch := s.take()
+	s.mu.Unlock()
+	return s.send(batch)
+}
+
+// Dropped returns the number of entries that were dropped because they
+// couldn't be sent.
+func (s *HTTPSink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close sends any pending entries and stops the sink.
+func (s *HTTPSink) Close() error {
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+	s.mu.Lock()
+	s.closed = true
+	batch := s.take()
+	s.mu.Unlock()
+	return s.send(batch)
+}
+
+// send partial batches every flush interval
+func (s *HTTPSink) flusher() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			batch := s.take()
+			s.mu.Unlock()
+			// errors are reported when the next batch fails too
+			s.send(batch)
+		}
+	}
+}
+
+// take the current batch. must be called while holding s.mu.
+func (s *HTTPSink) take() []Entry {
+	batch := s.batch
+	s.batch = nil
+	return batch
+}
+
+// send a batch, retrying with backoff. the batch is dropped if it
+// can't be sent.
+func (s *HTTPSink) send(batch []Entry) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	body, err := s.encode(batch)
+	if err != nil {
+		s.dropped.Add(uint64(len(batch)))
+		return err
+	}
+
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	wait := s.backoff
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		if retry, err = s.post(body); err == nil {
+			return nil
+		}
+		if !retry || attempt >= s.retries {
+			break
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+	s.dropped.Add(uint64(len(batch)))
+	return fmt.Errorf("dropped %d entries: %w", len(batch), err)
+}
+
+// post a request, reporting whether it's worth retrying if it fails
+func (s *HTTPSink) post(body []byte) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header = s.header.Clone()
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	// client errors other than rate limiting won't succeed if retried
+	retry = resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("log collector returned %s", resp.Status)
+}
+
+// encode a batch as a JSON array of entries in the same form as
+// FormatJSON log files
+func encodeJSONBatch(batch []Entry) ([]byte, error) {
+	entries := make([]jsonEntry, len(batch))
+	for i, e := range batch {
+		entries[i] = toJSONEntry(e)
+	}
+	return json.Marshal(entries)
+}
@@ -0,0 +1,181 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// a log collector recording the batches posted to it
+type collector struct {
+	mu      sync.Mutex
+	batches [][]map[string]any
+	headers []http.Header
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var batch []map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.batches = append(c.batches, batch)
+	c.headers = append(c.headers, r.Header)
+}
+
+func (c *collector) sizes() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sizes := make([]int, len(c.batches))
+	for i, b := range c.batches {
+		sizes[i] = len(b)
+	}
+	return sizes
+}
+
+func TestHTTPSinkBatches(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	sink := NewHTTPSink(srv.URL, HTTPBatchSize(3), HTTPFlushInterval(time.Hour), HTTPHeader("Authorization", "Bearer token"))
+	l, _ := NewBufferLogger("http", "1", WithSilentConsole(), WithSinks(sink))
+	for range 7 {
+		l.Info("entry")
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	sizes := c.sizes()
+	if len(sizes) != 3 || sizes[0] != 3 || sizes[1] != 3 || sizes[2] != 1 {
+		t.Errorf("batch sizes = %v, want [3 3 1]", sizes)
+	}
+	first := c.batches[0][0]
+	if first["component"] != "http" || first["level"] != INFO || first["message"] != "entry" {
+		t.Errorf("first entry = %v", first)
+	}
+	if got := c.headers[0].Get("Authorization"); got != "Bearer token" {
+		t.Errorf("Authorization header = %q", got)
+	}
+	if got := c.headers[0].Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+}
+
+func TestHTTPSinkFlushInterval(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	sink := NewHTTPSink(srv.URL, HTTPBatchSize(100), HTTPFlushInterval(20*time.Millisecond))
+	defer sink.Close()
+	sink.WriteEntry(Entry{Time: time.Now(), Level: INFO, Message: "partial batch"})
+	deadline := time.Now().Add(5 * time.Second)
+	for len(c.sizes()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("partial batch wasn't sent after the flush interval")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestHTTPSinkRetries(t *testing.T) {
+	var attempts atomic.Int32
+	c := &collector{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) <= 2 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		c.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	sink := NewHTTPSink(srv.URL, HTTPBatchSize(1), HTTPRetries(3, time.Millisecond))
+	if err := sink.WriteEntry(Entry{Level: INFO, Message: "retried"}); err != nil {
+		t.Fatalf("WriteEntry: %v", err)
+	}
+	sink.Close()
+	if n := attempts.Load(); n != 3 {
+		t.Errorf("got %d attempts, want 3", n)
+	}
+	if sink.Dropped() != 0 {
+		t.Errorf("dropped %d entries", sink.Dropped())
+	}
+}
+
+func TestHTTPSinkDropsOnPersistentFailure(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		http.Error(w, "down", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	tempLogDir(t)
+	sink := NewHTTPSink(srv.URL, HTTPBatchSize(2), HTTPRetries(2, time.Millisecond))
+	l := NewLogger("http", "1", WithSilentConsole(), WithSinks(sink))
+	for range 4 {
+		l.Info("entry")
+	}
+	entries := readLog(t, l)
+	l.Close()
+
+	if len(entries) != 4 {
+		t.Errorf("log file has %d entries, want all 4", len(entries))
+	}
+	if got := sink.Dropped(); got != 4 {
+		t.Errorf("Dropped = %d, want 4", got)
+	}
+	if got := l.DropStats()[DropSink]; got != 4 {
+		t.Errorf("DropStats()[DropSink] = %d, want 4", got)
+	}
+	// two batches, each tried once and retried twice
+	if n := attempts.Load(); n != 6 {
+		t.Errorf("got %d attempts, want 6", n)
+	}
+}
+
+func TestHTTPSinkDoesNotRetryClientErrors(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	sink := NewHTTPSink(srv.URL, HTTPBatchSize(1), HTTPRetries(3, time.Millisecond))
+	if err := sink.WriteEntry(Entry{Level: INFO}); err == nil {
+		t.Error("WriteEntry succeeded, want an error")
+	}
+	sink.Close()
+	if n := attempts.Load(); n != 1 {
+		t.Errorf("got %d attempts, want 1", n)
+	}
+}
+
+func TestHTTPSinkWriteAfterClose(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	sink := NewHTTPSink(srv.URL, HTTPBatchSize(1))
+	sink.Close()
+	if err := sink.WriteEntry(Entry{Level: INFO}); !errors.Is(err, ErrSinkClosed) {
+		t.Errorf("WriteEntry after Close = %v, want ErrSinkClosed", err)
+	}
+	if got := sink.Dropped(); got != 1 {
+		t.Errorf("Dropped = %d, want 1", got)
+	}
+	if sizes := c.sizes(); len(sizes) != 0 {
+		t.Errorf("sent batches %v after Close", sizes)
+	}
+}
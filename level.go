@@ -0,0 +1,261 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// level severities, used to order levels when filtering.
+// DEBUG < INFO < WARN < ERROR < FATAL
+// the values match slog's levels, leaving room to register
+// custom levels between them.
+var builtinLevels = map[string]int{
+	DEBUG: int(slog.LevelDebug),
+	INFO:  int(slog.LevelInfo),
+	WARN:  int(slog.LevelWarn),
+	ERROR: int(slog.LevelError),
+	FATAL: int(slogLevelFatal),
+}
+
+// registry of all known levels, including custom ones
+var (
+	levelsMu   sync.RWMutex
+	severities = func() map[string]int {
+		m := make(map[string]int, len(builtinLevels))
+		for name, sev := range builtinLevels {
+			m[name] = sev
+		}
+		return m
+	}()
+)
+
+// unknown levels that have already been warned about. only the first
+// maxWarnedLevels are remembered so levels built from dynamic strings
+// can't grow it without bound.
+var (
+	warnedMu      sync.Mutex
+	warnedLevels  = map[string]bool{}
+	warnedTooMany bool
+)
+
+const maxWarnedLevels = 100
+
+// default minimum level when none is configured
+const defaultLevel = INFO
+
+// ErrUnknownLevel is returned by LogE for levels that aren't registered.
+var ErrUnknownLevel = errors.New("unknown log level")
+
+// RegisterLevel registers a custom level with the given severity, which
+// determines how it's ordered against other levels when filtering. The
+// built-in levels use the same severities as slog: DEBUG is -4, INFO is 0,
+// WARN is 4, ERROR is 8, and FATAL is 12. For example, a TRACE level below
+// DEBUG could use -8. Level names are case insensitive and the built-in
+// levels can't be redefined. Registering a custom level again changes its
+// severity.
+func RegisterLevel(name string, severity int) error {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" {
+		return errors.New("level name can't be empty")
+	}
+	if _, ok := builtinLevels[name]; ok {
+		return fmt.Errorf("can't redefine built-in level %s", name)
+	}
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	severities[name] = severity
+	return nil
+}
+
+// parse a level name into its severity. returns false if
+// the level is unknown.
+func parseLevel(level string) (int, bool) {
+	// the built-in levels can't change, so they don't need the lock
+	if sev, ok := builtinLevels[level]; ok {
+		return sev, true
+	}
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	sev, ok := severities[strings.ToUpper(strings.TrimSpace(level))]
+	return sev, ok
+}
+
+// return the canonical upper case name of a registered level, so
+// "warn" and " Warn" are written as WARN. unregistered levels are
+// returned unchanged.
+func normalizeLevel(level string) string {
+	name := strings.ToUpper(strings.TrimSpace(level))
+	if _, ok := parseLevel(name); ok {
+		return name
+	}
+	return level
+}
+
+// return the name of the level with the given severity,
+// preferring built-in levels over custom ones.
+func nameForSeverity(sev int) (string, bool) {
+	for name, s := range builtinLevels {
+		if s == sev {
+			return name, true
+		}
+	}
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	for name, s := range severities {
+		if s == sev {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// return the level name for a given severity
+func levelName(sev int) string {
+	if name, ok := nameForSeverity(sev); ok {
+		return name
+	}
+	return fmt.Sprintf("LEVEL(%d)", sev)
+}
+
+// map a level name to a slog level for display. unknown
+// levels are displayed at INFO.
+func toSlogLevel(level string) slog.Level {
+	if sev, ok := parseLevel(level); ok {
+		return slog.Level(sev)
+	}
+	return slog.LevelInfo
+}
+
+// determine the minimum level from the LOG_LEVEL environment
+// variable. defaults to INFO if unset or invalid.
+func levelFromEnv() int {
+	if lvl, set := os.LookupEnv("LOG_LEVEL"); set {
+		if sev, ok := parseLevel(lvl); ok {
+			return sev
+		}
+	}
+	return builtinLevels[defaultLevel]
+}
+
+// SetLevel sets the minimum level that will be displayed and written
+// to the log file. Messages below this level are dropped before any
+// formatting takes place. Unknown levels are ignored.
+//
+// SetLevel is safe to call while other goroutines are logging, such as
+// from an HTTP handler an operator uses to turn on debug logging, and
+// takes effect immediately. Loggers derived from l with WithFields,
+// Child, or WithComponent start with l's level but keep their own, so
+// they aren't affected.
+func (l *Logger) SetLevel(level string) {
+	if sev, ok := parseLevel(level); ok {
+		l.level.Store(int64(sev))
+	}
+}
+
+// Level returns the current minimum log level.
+func (l *Logger) Level() string {
+	return levelName(int(l.level.Load()))
+}
+
+// enabled reports whether messages at the given level should be logged,
+// taking the minimum level, rate limit, and sampling into account.
+func (l *Logger) enabled(level string) bool {
+	if !l.levelEnabled(level) {
+		return false
+	}
+	return l.out.limit == nil || l.allow()
+}
+
+// levelEnabled reports whether the level is at or above the minimum level.
+func (l *Logger) levelEnabled(level string) bool {
+	sev, ok := parseLevel(level)
+	if !ok {
+		// always record unknown levels rather than silently drop them,
+		// but warn about them once since they can't be filtered.
+		warnUnknownLevel(level)
+		return true
+	}
+	return sev >= int(l.level.Load())
+}
+
+// warn about logging with an unregistered level the first time it's used
+func warnUnknownLevel(level string) {
+	warnedMu.Lock()
+	defer warnedMu.Unlock()
+	if warnedLevels[level] || warnedTooMany {
+		return
+	}
+	if len(warnedLevels) >= maxWarnedLevels {
+		warnedTooMany = true
+		log.Printf("logging with more than %d unregistered levels, not warning about any more", maxWarnedLevels)
+		return
+	}
+	warnedLevels[level] = true
+	log.Printf("logging with unregistered level %q", level)
+}
+
+// LogE is like Log, but returns an error wrapping ErrUnknownLevel rather
+// than writing the entry if the level isn't registered.
+func (l *Logger) LogE(level string, msg string) error {
+	if _, ok := parseLevel(level); !ok {
+		return fmt.Errorf("%w %q", ErrUnknownLevel, level)
+	}
+	if l.enabled(level) {
+		l.write(l.out.now(), level, msg, l.fields, l.caller(1))
+	}
+	return nil
+}
+
+// Logf logs a formatted message at any registered level and displays it.
+// Messages at unregistered levels are written verbatim, with a one time
+// warning, since they can't be filtered by level.
+func (l *Logger) Logf(level string, msg string, v ...any) {
+	if !l.enabled(level) {
+		return
+	}
+	l.emit(level, format(msg, v...), l.caller(1))
+}
+
+// LogAs writes an entry like Log, but with its Component and ID columns
+// set to component and id for this entry only, so a single shared logger
+// can log on behalf of many short-lived entities without opening a log
+// file for each of them. An empty component or id uses the logger's own.
+// The component is cleaned the same way as the one passed to NewLogger.
+func (l *Logger) LogAs(component string, id string, level string, msg string) {
+	if !l.enabled(level) {
+		return
+	}
+	l.writeEntry(l.entryAs(component, id, level, msg, l.caller(1)))
+}
+
+// LogAsf is like LogAs, but formats the message like Logf and displays
+// it, with the component and id as attributes.
+func (l *Logger) LogAsf(component string, id string, level string, msg string, v ...any) {
+	if !l.enabled(level) {
+		return
+	}
+	defer l.out.recoverPanic()
+	e := l.entryAs(component, id, level, format(msg, v...), l.caller(1))
+	l.display(context.Background(), level, e.Message, e.Source, "component", e.Component, "id", e.ID)
+	l.writeEntry(e)
+}
+
+// build an entry logged on behalf of another component and id, falling
+// back to the logger's own if they're empty.
+func (l *Logger) entryAs(component string, id string, level string, msg string, src string) Entry {
+	if component == "" {
+		component = l.component
+	} else {
+		component = l.out.cleanComponent(component)
+	}
+	if id == "" {
+		id = l.componentID
+	}
+	return Entry{Time: l.out.now(), Component: component, Level: level, Message: msg, ID: id, TaskID: l.taskID, Fields: l.fields, Source: src}
+}
@@ -0,0 +1,134 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSetLevel(t *testing.T) {
+	l, buf := NewBufferLogger("level", "1", WithSilentConsole())
+	l.SetLevel(WARN)
+	l.Info("dropped")
+	l.Warn("kept")
+	l.SetLevel("debug")
+	l.Debug("kept too")
+	l.SetLevel("nonsense")
+	if got := l.Level(); got != DEBUG {
+		t.Errorf("Level after an unknown level = %s, want DEBUG", got)
+	}
+	l.Close()
+	if out := buf.String(); strings.Contains(out, "dropped") || !strings.Contains(out, "kept") || !strings.Contains(out, "kept too") {
+		t.Errorf("log = %q", out)
+	}
+}
+
+// run with -race: levels are flipped while other goroutines log
+func TestSetLevelWhileLogging(t *testing.T) {
+	l := NewWriterLogger("level", "1", io.Discard, WithOutput(io.Discard))
+	defer l.Close()
+	child := l.Child("worker")
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					l.Debug("debug")
+					l.Info("info")
+					child.Warn("warn")
+					_ = l.Level()
+				}
+			}
+		}()
+	}
+	levels := []string{DEBUG, INFO, WARN, ERROR}
+	for i := range 1000 {
+		l.SetLevel(levels[i%len(levels)])
+		child.SetLevel(levels[(i+1)%len(levels)])
+	}
+	close(stop)
+	wg.Wait()
+
+	l.SetLevel(ERROR)
+	if got := l.Level(); got != ERROR {
+		t.Errorf("Level = %s, want ERROR", got)
+	}
+}
+
+func TestLogNormalizesLevel(t *testing.T) {
+	l, buf := NewBufferLogger("level", "1", WithSilentConsole())
+	l.Log("info", "lowercase")
+	l.Log(" Warn ", "padded")
+	l.Close()
+	records := bufferRecords(t, buf.String())
+	if records[0][2] != INFO || records[1][2] != WARN {
+		t.Errorf("levels written as %q and %q, want INFO and WARN", records[0][2], records[1][2])
+	}
+}
+
+func TestUnknownLevel(t *testing.T) {
+	var logged strings.Builder
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	l, buf := NewBufferLogger("level", "1", WithSilentConsole())
+	if err := l.LogE("eror", "typo"); !errors.Is(err, ErrUnknownLevel) {
+		t.Errorf("LogE with an unknown level = %v, want ErrUnknownLevel", err)
+	}
+	// Log records unknown levels, warning once
+	l.Log("bogus-level", "first")
+	l.Log("bogus-level", "second")
+	l.Close()
+
+	records := bufferRecords(t, buf.String())
+	if len(records) != 2 || records[0][2] != "bogus-level" {
+		t.Errorf("records = %q, want the two entries logged with Log", records)
+	}
+	if n := strings.Count(logged.String(), `unregistered level "bogus-level"`); n != 1 {
+		t.Errorf("warned %d times, want once:\n%s", n, logged.String())
+	}
+}
+
+func TestUnknownLevelWarningsCapped(t *testing.T) {
+	var logged strings.Builder
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	l, _ := NewBufferLogger("level", "1", WithSilentConsole())
+	defer l.Close()
+	for i := range 2 * maxWarnedLevels {
+		l.Log(fmt.Sprintf("dynamic-%d", i), "entry")
+	}
+	warnedMu.Lock()
+	n := len(warnedLevels)
+	warnedMu.Unlock()
+	if n > maxWarnedLevels {
+		t.Errorf("remembered %d warned levels, want at most %d", n, maxWarnedLevels)
+	}
+	if !strings.Contains(logged.String(), "not warning about any more") {
+		t.Errorf("no warning that further levels aren't reported:\n%s", logged.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for level, want := range map[string]int{INFO: 0, "info": 0, " Warn ": 4, FATAL: 12} {
+		if sev, ok := parseLevel(level); !ok || sev != want {
+			t.Errorf("parseLevel(%q) = %d, %t, want %d", level, sev, ok, want)
+		}
+	}
+	if _, ok := parseLevel("unknown"); ok {
+		t.Error("parseLevel(unknown) succeeded")
+	}
+}
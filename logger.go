@@ -1,14 +1,19 @@
 package logger
 
 import (
-	"encoding/csv"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"log/slog"
+	"math"
 	"os"
 	"path/filepath"
-	"sync"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"sync/atomic"
 	"time"
 )
 
@@ -19,14 +24,32 @@ to a single log file.
 
 Log messages are stored as .csv files using the following columns:
 Time, Component, Level, Message, ID
+
+Alternatively, messages can be stored as newline delimited JSON
+in .jsonl files. See Format.
 */
 type Logger struct {
-	mu          sync.Mutex   // lock so loggers don't over write each other
-	component   string       // name of the component this logger is attached to
-	componentID string       // ID of the component this logger is attached to
-	logfile     string       // absolute path to the csv log file
-	log         *slog.Logger // slog instance
-	csvWriter   *csv.Writer  // csv writer instance
+	component      string               // name of the component this logger is attached to
+	componentID    string               // ID of the component this logger is attached to
+	taskID         string               // ID of the unit of work entries are logged for, if set
+	fields         map[string]any       // structured fields attached to every entry
+	contextKeys    []any                // context keys whose values are added as fields
+	log            *slog.Logger         // slog instance
+	console        io.Writer            // where messages are displayed
+	errConsole     io.Writer            // where messages at or above errLevel are displayed, if split
+	errLevel       slog.Level           // minimum level displayed on errConsole
+	color          colorMode            // when to color levels in displayed messages
+	handlerOpts    *slog.HandlerOptions // options for the display handler
+	consoleFormat  *Format              // format messages are displayed in, if different from the log file's
+	silent         *atomic.Bool         // whether console output is disabled
+	level          *atomic.Int64        // minimum severity that will be logged
+	exitCode       *atomic.Int64        // exit code used by Fatal
+	callerSkip     int                  // extra stack frames skipped when recording source locations
+	sanitize       bool                 // whether to neutralize spreadsheet formulas in fields
+	escapeNewlines bool                 // whether to escape line breaks in fields
+	out            *output              // log file, shared with derived loggers and other loggers writing the same file
+	ref            *outputRef           // this logger's reference to out and its sinks, shared with derived loggers
+	redactor       *redactor            // redaction rules, shared with derived loggers
 }
 
 // Log levels
@@ -38,117 +61,783 @@ const (
 	FATAL string = "FATAL"
 )
 
+// Default permissions for created log directories and files. Both are
+// subject to the process umask. Directories need the execute bit
+// so they can be traversed; log files are only writable by the owner
+// and readable by the owner's group. See WithDirMode and WithFileMode.
+const (
+	defaultDirMode  os.FileMode = 0755
+	defaultFileMode os.FileMode = 0640
+)
+
 // Logger configs
-// instantiate a new logger
-func NewLogger(component string, id string) *Logger {
+// instantiate a new logger. the minimum log level is read from the
+// optional LOG_LEVEL environment variable, defaulting to INFO. the
+// optional LOG_FORMAT ("csv" or "json") and LOG_CONSOLE ("text", "json",
+// or "off") environment variables set the log file format and how
+// messages are displayed. options take precedence over environment
+// variables, which take precedence over the defaults.
+//
+// NewLogger exits the program if the log directory or file can't be
+// created or opened. Use NewLoggerE to handle these errors instead.
+func NewLogger(component string, id string, opts ...Option) *Logger {
+	l, err := NewLoggerE(component, id, opts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return l
+}
+
+// NewLoggerE instantiates a new logger, returning an error if the log
+// directory or file can't be created or opened. Control characters,
+// quotes, and the field delimiter in the component name are replaced
+// with underscores so the log file stays easy to search.
+//
+// Loggers in the same process writing to the same log files share them,
+// and the first logger to open them configures them. A later logger's
+// options for the file, such as its format, columns, rotation, buffering,
+// WithAsync, WithSequence, WithDedup, and rate limits, are ignored, as
+// are hooks added with OnLevel, which run for every entry written to the
+// file. Its console options, minimum level, redaction, and sinks are
+// always its own.
+func NewLoggerE(component string, id string, opts ...Option) (*Logger, error) {
+	l, err := newLogger(component, id, opts...)
+	if err != nil {
+		return nil, err
+	}
+
 	// place log file in an designated directory, or the current
 	// one if LOG_DIR is not set
 	logDir, set := os.LookupEnv("LOG_DIR")
 	if !set {
 		logDir, _ = os.Getwd()
 	}
-	// create the log file if it doesn't already exist
-	// log files have the name format: log-dd-mm-yyyy.csv, so
-	// one new log file should be created per day.
-	logFile := filepath.Join(logDir, fmt.Sprintf("log-%s.csv", getCurrentDate()))
-
-	// make sure the log directory exists. if not, create it.
-	if err := createLogDir(logDir); err != nil {
-		log.Fatalf("failed to create log directory: %v", err)
+	logDir, err = filepath.Abs(logDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve log directory %q: %w", logDir, err)
+	}
+	// log files have the name format: log-dd-mm-yyyy.csv (or .tsv, .jsonl),
+	// or log-component-dd-mm-yyyy.csv with WithPerComponentFile, so one new
+	// log file should be created per day. the date is taken in the same
+	// time zone as the entry timestamps so rows always land in the file
+	// for their day.
+	if l.out.perComponent {
+		l.out.fileComponent = fileComponent(l.component)
 	}
+	now := l.out.now().In(l.out.loc)
+	logFile := logFilePath(logDir, l.out.fileComponent, now, l.out.ext())
 
-	// create the log file if it doesn't already exist
-	if err := createLogFile(logFile); err != nil {
-		log.Fatalf("failed to create log file: %v", err)
+	// share the output of any other logger writing to the same log files.
+	// the file is already configured, so this logger's file options are
+	// ignored, but its redaction and sinks are its own.
+	key := outputKey(logDir, l.out.fileComponent, l.out.ext())
+	outputsMu.Lock()
+	defer outputsMu.Unlock()
+	if shared, ok := outputs[key]; ok {
+		shared.refs++
+		l.out = shared
+		l.ref.sinks.start()
+		return l, nil
 	}
 
-	// open for use by the CSV writer.
-	csvFile, err := os.OpenFile(logFile, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
+	csvFile, err := l.out.openLogFile(logDir, logFile)
 	if err != nil {
-		log.Fatalf("failed to open log file: %v", err)
+		if !l.out.fallbackConsole {
+			return nil, err
+		}
+		log.Printf("logging to the console only: %v", err)
+		l.out.closed = true
+		l.ref.sinks.start()
+		return l, nil
+	}
+	l.out.dir = logDir
+	l.out.path = logFile
+	l.out.nextDay = nextMidnight(now)
+	l.out.setFile(csvFile)
+	l.out.cleanup(now)
+	if l.out.flushInterval > 0 {
+		l.out.startFlusher()
 	}
-	return &Logger{
+	if l.out.asyncSize > 0 {
+		l.out.startAsync()
+	}
+	if l.out.limit != nil {
+		l.out.limit.startSummaries(l)
+	}
+	l.ref.sinks.start()
+	l.out.key = key
+	l.out.refs = 1
+	outputs[key] = l.out
+	return l, nil
+}
+
+// create a logger with the default settings, the environment, and the
+// given options applied, ready to be given an output.
+func newLogger(component string, id string, opts ...Option) (*Logger, error) {
+	l := &Logger{
 		component:   component,
 		componentID: id,
-		logfile:     logFile,
-		csvWriter:   csv.NewWriter(csvFile),
-		log:         slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		console:     os.Stdout,
+		level:       new(atomic.Int64),
+		silent:      new(atomic.Bool),
+		exitCode:    new(atomic.Int64),
+		sanitize:    true,
+		ref:         new(outputRef),
+		redactor:    new(redactor),
+		out: &output{
+			dirMode:    defaultDirMode,
+			fileMode:   defaultFileMode,
+			loc:        time.UTC,
+			now:        time.Now,
+			columns:    DefaultColumns(),
+			timeFormat: defaultTimeFormat,
+			flushLevel: int(slog.LevelError),
+			comma:      ',',
+		},
+	}
+	l.level.Store(int64(levelFromEnv()))
+	l.exitCode.Store(1)
+	l.applyEnv()
+	for _, opt := range opts {
+		opt(l)
 	}
+	l.component = l.out.cleanComponent(l.component)
+	if l.out.host == "" {
+		l.out.host = hostname()
+	}
+	l.out.addHostColumn()
+	if l.out.syncWrites && (l.out.buffered() || l.out.asyncSize > 0) {
+		return nil, errors.New("WithSync can't be combined with buffered or async writes")
+	}
+	if l.out.fileLock && l.out.buffered() {
+		return nil, errors.New("WithFileLock can't be combined with buffered writes")
+	}
+	l.log = slog.New(l.consoleHandler())
+	return l, nil
+}
+
+// NewLoggerWithFormat instantiates a new logger that writes its log file
+// in the given format. Like NewLogger, it exits the program if the log
+// file can't be created or opened.
+func NewLoggerWithFormat(component string, id string, format Format, opts ...Option) *Logger {
+	return NewLogger(component, id, append([]Option{WithFormat(format)}, opts...)...)
+}
+
+// NewComponentLogger instantiates a new logger for a component without an
+// ID. Since there's no ID, csv log files are written without the ID column:
+// Time, Component, Level, and Message. Pass WithColumns to use a different
+// layout, such as WithColumns(DefaultColumns()...) to keep an empty ID
+// column. Like NewLogger, it exits the program if the log file can't be
+// created or opened.
+func NewComponentLogger(component string, opts ...Option) *Logger {
+	noID := WithColumns(ColumnTime, ColumnComponent, ColumnLevel, ColumnMessage)
+	return NewLogger(component, "", append([]Option{noID}, opts...)...)
+}
+
+// slog has no fatal level, so use one above slog.LevelError
+const slogLevelFatal = slog.LevelError + 4
+
+// options for the display handler. all levels are let through since
+// filtering is handled by the logger's own minimum level, unless a
+// console level was set with WithHandlerOptions. displayed attributes
+// are redacted if console redaction is enabled.
+func (l *Logger) handlerOptions() *slog.HandlerOptions {
+	opts := &slog.HandlerOptions{Level: slog.Level(math.MinInt)}
+	var replace func([]string, slog.Attr) slog.Attr
+	if l.handlerOpts != nil {
+		if l.handlerOpts.Level != nil {
+			opts.Level = l.handlerOpts.Level
+		}
+		replace = l.handlerOpts.ReplaceAttr
+	}
+	r := l.redactor
+	opts.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.LevelKey && len(groups) == 0 {
+			// display fatal and custom levels by name rather than
+			// as an offset from one of slog's levels
+			if lvl, ok := a.Value.Any().(slog.Level); ok {
+				if name, ok := nameForSeverity(int(lvl)); ok {
+					a.Value = slog.StringValue(name)
+				}
+			}
+		}
+		if r.console && r.redacting() {
+			a = r.redactAttr(a)
+		}
+		if replace != nil {
+			a = replace(groups, a)
+		}
+		return a
+	}
+	return opts
 }
 
-// return todays date as dd-mm-yyyy
-func getCurrentDate() string {
-	now := time.Now()
-	return fmt.Sprintf("%02d-%02d-%d", now.Day(), now.Month(), now.Year())
+// return the given date as dd-mm-yyyy
+func formatDate(t time.Time) string {
+	return fmt.Sprintf("%02d-%02d-%d", t.Day(), t.Month(), t.Year())
 }
 
-// make sure the log directory exists. if not, create it.
-func createLogDir(logDirPath string) error {
-	if _, err := os.Stat(logDirPath); errors.Is(err, os.ErrNotExist) {
-		if err := os.Mkdir(logDirPath, 0666); err != nil {
-			return fmt.Errorf("failed to create log directory: %v", err)
+// make sure the log directory exists and is writable, then create the log
+// file if it doesn't already exist and open it for use by the csv writer.
+func (o *output) openLogFile(logDir, logFile string) (*os.File, error) {
+	if err := createLogDir(logDir, dirPerm(o.dirMode)); err != nil {
+		return nil, fmt.Errorf("failed to create log directory %q: %w", logDir, err)
+	}
+	// new files are created in the directory at every rollover and
+	// rotation, so check it's writable even if the log file exists.
+	tmp, err := os.CreateTemp(logDir, ".logger-*")
+	if err != nil {
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) {
+			// the temp file's name isn't useful
+			err = pathErr.Err
 		}
+		return nil, fmt.Errorf("log directory %q is not writable: %w", logDir, err)
+	}
+	tmp.Close()
+	os.Remove(tmp.Name())
+	return o.openFile(logFile)
+}
+
+// make sure the log directory exists. if not, create it along
+// with any missing parent directories.
+func createLogDir(logDirPath string, mode os.FileMode) error {
+	info, err := os.Stat(logDirPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return os.MkdirAll(logDirPath, mode)
 	} else if err != nil {
-		return fmt.Errorf("failed to get log dir stats: %v", err)
+		return fmt.Errorf("failed to get log dir stats: %w", err)
+	}
+	if !info.IsDir() {
+		return errors.New("not a directory")
 	}
 	return nil
 }
 
-// create a log file if it doesn't exist
-func createLogFile(lfpath string) error {
-	if _, err := os.Stat(lfpath); errors.Is(err, os.ErrNotExist) {
-		csvFile, err := os.Create(lfpath)
-		if err != nil {
-			return err
-		}
-		defer csvFile.Close()
-		if err := csvFile.Chmod(0777); err != nil {
-			return err
+// create a log file if it doesn't exist. the file is created with
+// the configured mode, subject to the process umask. csv files start
+// with a header row; json files have none. the file is created
+// exclusively so that if another logger or process creates it at the
+// same time, only one of them writes the header.
+func (o *output) createLogFile(lfpath string) error {
+	csvFile, err := os.OpenFile(lfpath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, filePerm(o.fileMode))
+	if errors.Is(err, os.ErrExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer csvFile.Close()
+	if !o.hasHeader() {
+		return nil
+	}
+	// add initial column names
+	return o.writeHeaderFile(csvFile)
+}
+
+// format the message with the given arguments. if there are no
+// arguments the message is returned verbatim so literal '%' characters
+// aren't treated as formatting verbs.
+func format(msg string, v ...any) string {
+	if len(v) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, v...)
+}
+
+// display the message and write it to the log file.
+func (l *Logger) emit(level string, msg string, src string) {
+	defer l.out.recoverPanic()
+	l.display(context.Background(), level, msg, src)
+	l.write(l.out.now(), level, msg, l.fields, src)
+}
+
+// reports whether attrs, as alternating keys and values, has the given key
+func hasAttr(attrs []any, key string) bool {
+	for i := 0; i < len(attrs); i += 2 {
+		if attrs[i] == key {
+			return true
 		}
-		// add initial column names
-		writer := csv.NewWriter(csvFile)
-		writer.Write([]string{"Time", "Component", "Level", "Message", "ID"})
-		writer.Flush()
 	}
-	return nil
+	return false
+}
+
+// display the message with the given attributes, unless console output is disabled.
+func (l *Logger) display(ctx context.Context, level string, msg string, src string, attrs ...any) {
+	if l.silent.Load() {
+		return
+	}
+	if l.taskID != "" && !hasAttr(attrs, taskIDKey) {
+		// unless it's overridden, such as by the context
+		attrs = append(attrs, taskIDKey, l.taskID)
+	}
+	if src != "" {
+		attrs = append(attrs, slog.SourceKey, src)
+	}
+	l.log.Log(ctx, toSlogLevel(level), msg, attrs...)
 }
 
-// Info logs at LevelInfo and displays the message.
+// Info logs at LevelInfo and displays the message. Like the other level
+// methods, msg is used as a fmt.Sprintf format string when arguments are
+// given, so messages built from untrusted input should be logged with
+// Infoln instead, which never formats.
 func (l *Logger) Info(msg string, v ...any) {
-	l.log.Info(fmt.Sprintf(msg, v...))
-	l.Log(INFO, fmt.Sprintf(msg, v...))
+	if !l.enabled(INFO) {
+		return
+	}
+	l.emit(INFO, format(msg, v...), l.caller(1))
 }
 
 // Debug logs at LevelDebug and displays the message.
 func (l *Logger) Debug(msg string, v ...any) {
-	l.log.Debug(fmt.Sprintf(msg, v...))
-	l.Log(DEBUG, fmt.Sprintf(msg, v...))
+	if !l.enabled(DEBUG) {
+		return
+	}
+	l.emit(DEBUG, format(msg, v...), l.caller(1))
 }
 
 // Warn logs at LevelWarn and displays the message.
 func (l *Logger) Warn(msg string, v ...any) {
-	l.log.Warn(fmt.Sprintf(msg, v...))
-	l.Log(WARN, fmt.Sprintf(msg, v...))
+	if !l.enabled(WARN) {
+		return
+	}
+	l.emit(WARN, format(msg, v...), l.caller(1))
 }
 
 // Error logs at LevelError and displays the error message
 func (l *Logger) Error(msg string, v ...any) {
-	l.log.Error(fmt.Sprintf(msg, v...))
-	l.Log(ERROR, fmt.Sprintf(msg, v...))
+	if !l.enabled(ERROR) {
+		return
+	}
+	l.emit(ERROR, format(msg, v...), l.caller(1))
+}
+
+// Infoln logs msg verbatim at LevelInfo and displays it, without treating
+// it as a format string.
+func (l *Logger) Infoln(msg string) {
+	if !l.enabled(INFO) {
+		return
+	}
+	l.emit(INFO, msg, l.caller(1))
+}
+
+// Debugln logs msg verbatim at LevelDebug and displays it, without
+// treating it as a format string.
+func (l *Logger) Debugln(msg string) {
+	if !l.enabled(DEBUG) {
+		return
+	}
+	l.emit(DEBUG, msg, l.caller(1))
+}
+
+// Warnln logs msg verbatim at LevelWarn and displays it, without treating
+// it as a format string.
+func (l *Logger) Warnln(msg string) {
+	if !l.enabled(WARN) {
+		return
+	}
+	l.emit(WARN, msg, l.caller(1))
+}
+
+// Errorln logs msg verbatim at LevelError and displays it, without
+// treating it as a format string.
+func (l *Logger) Errorln(msg string) {
+	if !l.enabled(ERROR) {
+		return
+	}
+	l.emit(ERROR, msg, l.caller(1))
+}
+
+// Fatal logs at LevelFatal, displays the message, then exits the program.
+// The log entry is flushed to the log file before exiting. The exit code
+// defaults to 1 and can be changed with SetExitCode.
+func (l *Logger) Fatal(msg string, v ...any) {
+	l.fatal(format(msg, v...), l.caller(1))
+}
+
+// log a fatal message and exit
+func (l *Logger) fatal(msg string, src string) {
+	if l.enabled(FATAL) {
+		// emit releases the lock before returning, so flushing and exiting
+		// afterwards won't lose the entry or hold the mutex.
+		l.emit(FATAL, msg, src)
+	}
+	if err := l.out.sync(); err != nil {
+		log.Printf("failed to flush log file: %v", err)
+	}
+	os.Exit(int(l.exitCode.Load()))
+}
+
+// SetConsole enables or disables displaying messages. When disabled,
+// entries are still written to the log file. It's safe to call while
+// other goroutines are logging.
+func (l *Logger) SetConsole(enabled bool) {
+	l.silent.Store(!enabled)
+}
+
+// SetExitCode sets the exit code used by Fatal. It's safe to call while
+// other goroutines are logging.
+func (l *Logger) SetExitCode(code int) {
+	l.exitCode.Store(int64(code))
 }
 
 // Log writes a log entry to the CSV file. Does not display the message.
 // All logging csv files use the columns: timestamp, component, level, message, and ID.
 // The component and timestamp are provided by Log(), assuming
-// Logger was instantiated correctly.
+// Logger was instantiated correctly. Messages below the minimum
+// log level are dropped. Registered levels are case insensitive and
+// written in upper case. Unregistered levels are written as given, with
+// a one time warning; use LogE to reject them instead. If the logger has
+// fields attached, they are written as a JSON object in an additional
+// column.
 func (l *Logger) Log(level string, msg string) {
-	l.mu.Lock()
-	defer l.mu.Unlock()
+	if !l.enabled(level) {
+		return
+	}
+	l.write(l.out.now(), level, msg, l.fields, l.caller(1))
+}
+
+// Write writes an entry to the log file, giving full control over its
+// contents, such as to backfill historical entries with their original
+// timestamps. Like Log, the entry isn't displayed, and it's dropped if
+// it's below the minimum log level or rate limited. An entry without a
+// time is given the current time, and one without a component, ID, or
+// fields uses the logger's. Source is only written if WithSource is
+// enabled. The level methods, such as Info, write their entries the
+// same way after displaying them.
+//
+// Write returns an error wrapping ErrUnknownLevel if the level isn't
+// registered, ErrClosed if the logger has been closed, or the error
+// writing the entry to the log file, in which case it's written to
+// stderr instead. Errors writing entries queued by WithAsync aren't
+// returned; use Err to check for them.
+func (l *Logger) Write(e Entry) error {
+	if _, ok := parseLevel(e.Level); !ok {
+		return fmt.Errorf("%w %q", ErrUnknownLevel, e.Level)
+	}
+	if !l.enabled(e.Level) {
+		return nil
+	}
+	return l.writeEntry(l.withDefaults(e))
+}
+
+// fill in the time, component, ID, and fields of an entry that doesn't
+// have them with the current time and the logger's.
+func (l *Logger) withDefaults(e Entry) Entry {
+	if e.Time.IsZero() {
+		e.Time = l.out.now()
+	}
+	if e.Component == "" {
+		e.Component = l.component
+	}
+	if e.ID == "" {
+		e.ID = l.componentID
+	}
+	if e.TaskID == "" {
+		e.TaskID = l.taskID
+	}
+	if e.Fields == nil {
+		e.Fields = l.fields
+	}
+	return e
+}
+
+// write an entry with the given timestamp, fields, and source location
+// to the log file, or queue it if writing asynchronously.
+func (l *Logger) write(t time.Time, level string, msg string, fields map[string]any, src string) {
+	l.writeEntry(Entry{Time: t, Component: l.component, Level: level, Message: msg, ID: l.componentID, TaskID: l.taskID, Fields: fields, Source: src})
+}
+
+// write an entry to the log file, or queue it if writing asynchronously.
+// the source location is only written if enabled.
+func (l *Logger) writeEntry(e Entry) error {
+	if l.ref.closed.Load() {
+		// another logger may still be writing to the file
+		return ErrClosed
+	}
+	e.Level = normalizeLevel(e.Level)
+	if !l.out.throttle.allow(e, l.out.now()) {
+		l.out.drops.throttle.Add(1)
+		return nil
+	}
+	if !l.out.addSource {
+		// only displayed
+		e.Source = ""
+	}
+	if l.redactor.redacting() {
+		e.Message, e.Fields = l.redactor.redact(e.Message), l.redactor.redactFields(e.Fields)
+	}
+	l.out.counts.add(e.Level)
+	// sequence numbers are assigned when the entry is queued or written
+	e.Seq = 0
+	if l.out.async != nil {
+		l.out.async.enqueue(asyncEntry{l: l, e: e})
+		return nil
+	}
+	return l.writeNow(e, true)
+}
+
+// write an entry to the log file on the calling goroutine and run any
+// hooks, or queue them if it's the async writer. the file is only flushed if flush is true.
+func (l *Logger) writeNow(e Entry, flush bool) error {
+	defer l.out.recoverPanic()
+	written, err := l.writeFile(&e, flush)
+	if written {
+		if l.out.async != nil {
+			l.out.async.queueHooks(l.out, e)
+		} else {
+			l.out.runHooks(e)
+		}
+		l.ref.sinks.send(e)
+	}
+	l.warnLowDisk()
+	return err
+}
+
+// write an entry to the log file, giving it a sequence number if enabled
+// and it doesn't have one yet. returns false if it wasn't written because
+// the file is closed or the entry is being held as a repeat, and the error
+// if it couldn't be written to the file.
+func (l *Logger) writeFile(e *Entry, flush bool) (bool, error) {
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	if l.out.closed {
+		return false, ErrClosed
+	}
+	if l.out.sequence && e.Seq == 0 {
+		e.Seq = l.out.seq.Add(1)
+	}
+	if l.out.dedup != nil && l.out.holdRepeat(l, *e) {
+		return false, nil
+	}
+	if !l.encode(*e) {
+		return true, l.out.err
+	}
+	// the file lock is released by flushing, so it's never held between
+	// entries
+	force := l.out.critical(e.Level) || l.out.locked
+	if (flush || force) && !l.out.autoFlush(force) {
+		l.fallback(*e)
+		return true, l.out.err
+	}
+	return true, nil
+}
+
+// recover from a panic in the logging path so a bug, such as in a custom
+// column, can't crash the program. the panic is reported on stderr and
+// counted.
+func (o *output) recoverPanic() {
+	if r := recover(); r != nil {
+		o.panics.Add(1)
+		fmt.Fprintf(os.Stderr, "logger: recovered from panic: %v\n%s", r, debug.Stack())
+	}
+}
+
+// write an entry that couldn't be written to the log file to stderr
+// instead, so it isn't lost entirely.
+func (l *Logger) fallback(e Entry) {
+	timestamp := formatTime(e.Time.In(l.out.loc), l.out.timeFormat)
+	fmt.Fprintf(os.Stderr, "%s %s %s %s %s\n", timestamp, e.Component, e.Level, e.Message, e.ID)
+}
+
+// encode an entry into the log file's write buffer, rolling over or
+// rotating the file first if needed. returns false if the entry couldn't
+// be written, in which case it's written to stderr instead.
+// must be called while holding l.out.mu.
+func (l *Logger) encode(e Entry) bool {
+	if l.out.file != nil {
+		now := l.out.now().In(l.out.loc)
+		if !l.out.fixedPath && !now.Before(l.out.nextDay) {
+			l.out.rollover(now)
+		}
+		if l.out.maxSize > 0 && l.out.size >= l.out.maxSize || l.out.maxLines > 0 && l.out.lines >= l.out.maxLines {
+			if err := l.out.rotate(); err != nil {
+				l.out.err = err
+				log.Print(err)
+			}
+		}
+	}
+
+	l.out.lock()
+	timestamp := formatTime(e.Time.In(l.out.loc), l.out.timeFormat)
+	var err error
+	switch {
+	case l.out.formatter != nil:
+		e.Time = e.Time.In(l.out.loc)
+		err = l.out.writeFormatted(e)
+	case l.out.format == FormatJSON:
+		err = l.out.writeJSON(jsonEntry{
+			Time:      timestamp,
+			Component: e.Component,
+			Level:     e.Level,
+			Message:   e.Message,
+			ID:        e.ID,
+			TaskID:    e.TaskID,
+			Host:      l.out.jsonHost(),
+			Seq:       e.Seq,
+			Source:    e.Source,
+			Fields:    jsonFields(e.Fields),
+		})
+	default:
+		record := l.row(l.out.csvRecord(e, timestamp)...)
+		if l.out.sequence {
+			record = append(record, strconv.FormatUint(e.Seq, 10))
+		}
+		if l.out.addSource {
+			record = append(record, e.Source)
+		}
+		if len(e.Fields) > 0 {
+			record = append(record, encodeFields(e.Fields))
+		} else if l.out.extraColumn {
+			record = append(record, "")
+		}
+		err = l.out.writeCSV(record)
+	}
+	if err != nil {
+		// don't take down the program because of a logging failure. record
+		// the error and fall back to stderr so the entry isn't lost entirely.
+		l.out.fail(err)
+		l.fallback(e)
+		return false
+	}
+	l.out.lines++
+	// checked after the entry is written so a warning follows it in sequence
+	l.checkDisk()
+	return true
+}
+
+// LogBatch writes several entries to the log file at once, taking the lock
+// and flushing only once for the whole batch. Like Log, the entries aren't
+// displayed and those below the minimum log level are dropped. Entries
+// without a time are given the current time, and entries without a
+// component, ID, or fields use the logger's.
+func (l *Logger) LogBatch(entries []Entry) {
+	defer l.out.recoverPanic()
+	for _, e := range l.writeBatch(entries) {
+		l.out.runHooks(e)
+		l.ref.sinks.send(e)
+	}
+	l.warnLowDisk()
+}
+
+// write a batch of entries to the log file, returning the ones written.
+func (l *Logger) writeBatch(entries []Entry) []Entry {
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	if l.out.closed || l.ref.closed.Load() {
+		return nil
+	}
+	// keep held repeats in order ahead of the batch
+	l.out.flushRepeats()
+	written := make([]Entry, 0, len(entries))
+	for _, e := range entries {
+		if !l.levelEnabled(e.Level) {
+			continue
+		}
+		e = l.withDefaults(e)
+		e.Level = normalizeLevel(e.Level)
+		e.Seq = 0
+		if l.out.sequence {
+			e.Seq = l.out.seq.Add(1)
+		}
+		if l.redactor.redacting() {
+			e.Message, e.Fields = l.redactor.redact(e.Message), l.redactor.redactFields(e.Fields)
+		}
+		l.out.counts.add(e.Level)
+		l.encode(e)
+		written = append(written, e)
+	}
+	critical := slices.ContainsFunc(written, func(e Entry) bool { return l.out.critical(e.Level) })
+	l.out.autoFlush(critical)
+	return written
+}
+
+// Err returns the most recent error encountered while writing to the
+// log file, or nil if there hasn't been one. Entries that fail to be
+// written to the log file are written to stderr instead.
+func (l *Logger) Err() error {
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	return l.out.err
+}
+
+// FilePath returns the absolute path of the log file currently being
+// written to. It changes when the logger rolls over to a new day, and
+// is empty if the logger is only displaying messages because the log
+// file couldn't be opened.
+func (l *Logger) FilePath() string {
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	return l.out.path
+}
+
+// Flush writes any buffered or queued entries to the log file, including
+// entries waiting to be written in async mode and repeats held back by
+// WithDedup. It's safe to call concurrently with logging and any number
+// of times, such as before shutting down. Calling Flush after Close is
+// a no-op.
+func (l *Logger) Flush() error {
+	return l.out.sync()
+}
+
+// Close flushes any pending entries and closes the log file.
+// Subsequent log calls will still be displayed but are no longer
+// written to the log file. Calling Close more than once is a no-op.
+// Since derived loggers share the parent's log file, closing any
+// of them closes the file for all of them. If other loggers created
+// with NewLogger write to the same file, it's only closed once all
+// of them have been closed, but the closed logger stops writing to it
+// right away and Write returns ErrClosed.
+func (l *Logger) Close() error {
+	var err error
+	l.ref.once.Do(func() {
+		l.ref.closed.Store(true)
+		err = releaseOutput(l.out)
+		// closed after the output so entries still queued for the async
+		// writer reach them
+		if serr := l.ref.sinks.close(); err == nil {
+			err = serr
+		}
+	})
+	return err
+}
+
+// Shutdown closes the logger like Close, writing queued and buffered
+// entries to the log file and sinks first, but gives up waiting once ctx
+// is done, such as when a shutdown deadline passes on a slow disk. If ctx
+// is done first, Shutdown returns an error wrapping ctx.Err() reporting
+// how many entries were still queued and bytes still buffered, while
+// closing carries on in the background. Either way the logger is closed
+// afterwards, and entries logged after Shutdown aren't written to the
+// log file.
+func (l *Logger) Shutdown(ctx context.Context) error {
+	// refuse new entries now rather than once the closing goroutine gets
+	// to it, so none are logged after Shutdown returns early
+	l.ref.closed.Store(true)
+	done := make(chan error, 1)
+	go func() { done <- l.Close() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		entries, bytes := l.out.pending()
+		for _, r := range l.ref.sinks {
+			entries += len(r.entries)
+		}
+		return fmt.Errorf("%w: %d entries still queued and %d bytes still buffered", ctx.Err(), entries, bytes)
+	}
+}
 
-	timestamp := time.Now().UTC()
-	l.csvWriter.Write([]string{timestamp.Format(time.RFC3339), l.component, level, msg, l.componentID})
-	l.csvWriter.Flush()
-	if err := l.csvWriter.Error(); err != nil {
-		log.Fatalf("error writing to log file: %v", err)
+// count the entries queued for the async writer, and the bytes
+// buffered for the log file, without waiting for a lock that's held.
+func (o *output) pending() (entries int, bytes int) {
+	if o.async != nil {
+		entries += len(o.async.entries)
+	}
+	if o.mu.TryLock() {
+		if !o.closed {
+			bytes = o.buf.Buffered()
+		}
+		o.mu.Unlock()
 	}
+	return entries, bytes
 }
@@ -0,0 +1,110 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// set LOG_DIR to a temporary directory for the test and return it
+func tempLogDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("LOG_DIR", dir)
+	return dir
+}
+
+// flush the logger and read back the entries in its log file
+func readLog(t *testing.T, l *Logger) []Entry {
+	t.Helper()
+	if err := l.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	entries, err := ReadEntries(l.FilePath())
+	if err != nil {
+		t.Fatalf("ReadEntries: %v", err)
+	}
+	return entries
+}
+
+// a sink that keeps the entries written to it in memory
+type memSink struct {
+	mu      sync.Mutex
+	entries []Entry
+	closed  bool
+}
+
+func (s *memSink) WriteEntry(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, e)
+	return nil
+}
+
+func (s *memSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *memSink) messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msgs := make([]string, len(s.entries))
+	for i, e := range s.entries {
+		msgs[i] = e.Message
+	}
+	return msgs
+}
+
+func TestNestedLogDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs", "app", "component")
+	t.Setenv("LOG_DIR", dir)
+	l, err := NewLoggerE("nested", "1", WithSilentConsole())
+	if err != nil {
+		t.Fatalf("NewLoggerE: %v", err)
+	}
+	defer l.Close()
+	l.Info("written")
+	if filepath.Dir(l.FilePath()) != dir {
+		t.Errorf("log file %q isn't in %q", l.FilePath(), dir)
+	}
+	if entries := readLog(t, l); len(entries) != 1 || entries[0].Message != "written" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestLogDirIsNotADirectory(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LOG_DIR", file)
+	_, err := NewLoggerE("nested", "1", WithSilentConsole())
+	if err == nil || !strings.Contains(err.Error(), "not a directory") {
+		t.Errorf("NewLoggerE with LOG_DIR set to a file = %v, want a not a directory error", err)
+	}
+}
+
+func TestRelativeLogDir(t *testing.T) {
+	root := t.TempDir()
+	t.Chdir(root)
+	t.Setenv("LOG_DIR", filepath.Join("logs", "relative"))
+	l, err := NewLoggerE("relative", "1", WithSilentConsole())
+	if err != nil {
+		t.Fatalf("NewLoggerE: %v", err)
+	}
+	defer l.Close()
+	path := l.FilePath()
+	if !filepath.IsAbs(path) {
+		t.Errorf("FilePath = %q, want an absolute path", path)
+	}
+	root, _ = filepath.EvalSymlinks(root)
+	dir, _ := filepath.EvalSymlinks(filepath.Dir(path))
+	if want := filepath.Join(root, "logs", "relative"); dir != want {
+		t.Errorf("log file is in %q, want %q", dir, want)
+	}
+}
@@ -0,0 +1,179 @@
+package logger
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MergeRange writes the entries of the daily csv log files in dir from
+// the day of from through the day of to, inclusive, to out as a single csv
+// file with one header, such as to export a range of logs to share. Files
+// are written in chronological order, with each day's rotated backups
+// before its current file, and compressed files are decompressed. Days
+// without a log file are skipped. Days are taken in from's time zone.
+//
+// Rows are copied as they are, so the files must all have the same header
+// and schema version line, which are written before the rows; if one
+// doesn't, MergeRange stops there and returns an error wrapping
+// ErrHeaderMismatch, leaving the files before it written to out. Files
+// written with WithPerComponentFile, json lines files, and .tsv files
+// aren't included. An error is returned if there are no log files in the
+// range.
+func MergeRange(dir string, from, to time.Time, out io.Writer) error {
+	files, err := logFilesInRange(dir, from, to)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no log files in %q from %s to %s", dir, formatDate(from), formatDate(to.In(from.Location())))
+	}
+
+	w := bufio.NewWriter(out)
+	var header mergedHeader
+	for _, path := range files {
+		if err := mergeFile(w, path, &header); err != nil {
+			w.Flush()
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// a daily log file and where it belongs in the merged output
+type datedLogFile struct {
+	path   string
+	date   time.Time
+	backup int // number of the rotated backup, 0 for the current file
+}
+
+// list the shared csv log files in dir dated from the day of from to the
+// day of to, in chronological order
+func logFilesInRange(dir string, from, to time.Time) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log directory %q: %w", dir, err)
+	}
+	loc := from.Location()
+	first := startOfDay(from)
+	last := startOfDay(to.In(loc))
+
+	var files []datedLogFile
+	for _, e := range entries {
+		m := logFilePattern.FindStringSubmatch(e.Name())
+		// skip per component files, whose names don't start with the date
+		if e.IsDir() || m == nil || m[3] != "csv" || !strings.HasPrefix(e.Name(), "log-"+m[1]) {
+			continue
+		}
+		date, ok := parseLogFileDate(e.Name(), loc)
+		if !ok || date.Before(first) || date.After(last) {
+			continue
+		}
+		backup := 0
+		if m[2] != "" {
+			backup, _ = strconv.Atoi(m[2][1:])
+		}
+		files = append(files, datedLogFile{filepath.Join(dir, e.Name()), date, backup})
+	}
+	slices.SortFunc(files, func(a, b datedLogFile) int {
+		if c := a.date.Compare(b.date); c != 0 {
+			return c
+		}
+		// higher numbered backups are older, and the current file is newest
+		return cmp.Compare(backupOrder(b.backup), backupOrder(a.backup))
+	})
+
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.path
+	}
+	return paths, nil
+}
+
+// order of a file among its day's files, with the current file last
+func backupOrder(backup int) int {
+	if backup == 0 {
+		return -1
+	}
+	return backup
+}
+
+// return midnight at the start of t's day
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// the schema version and header row of the first file merged, which the
+// others are checked against
+type mergedHeader struct {
+	version int    // schema version, 0 if the file has no schema line
+	line    string // header row, empty until a file with one is merged
+}
+
+// copy the rows of a log file to w, writing its schema version line and
+// header first if it's the first file.
+func mergeFile(w *bufio.Writer, path string, header *mergedHeader) error {
+	f, err := openLogReader(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	version, err := readSchemaLine(br)
+	if err != nil {
+		return fmt.Errorf("failed to read log file header of %q: %w", path, err)
+	}
+	// column names never contain line breaks, so the header is one line
+	line, err := br.ReadString('\n')
+	if errors.Is(err, io.EOF) && line == "" {
+		return nil
+	} else if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read log file header of %q: %w", path, err)
+	}
+	line = strings.TrimRight(line, "\r\n")
+	switch {
+	case header.line == "":
+		*header = mergedHeader{version: version, line: line}
+		if version > 0 {
+			fmt.Fprintf(w, "%s%d\n", schemaLinePrefix, version)
+		}
+		w.WriteString(line + "\n")
+	case line != header.line:
+		return fmt.Errorf("%w: %q has header %q, expected %q", ErrHeaderMismatch, path, line, header.line)
+	case version != header.version:
+		return fmt.Errorf("%w: %q has schema version %d, expected %d", ErrHeaderMismatch, path, version, header.version)
+	}
+
+	// make sure a file cut off mid row doesn't run into the next one
+	last := &lastByteWriter{w: w, last: '\n'}
+	if _, err := io.Copy(last, br); err != nil {
+		return fmt.Errorf("failed to read log file %q: %w", path, err)
+	}
+	if last.last != '\n' {
+		w.WriteByte('\n')
+	}
+	return nil
+}
+
+// remembers the last byte written through it
+type lastByteWriter struct {
+	w    io.Writer
+	last byte
+}
+
+func (l *lastByteWriter) Write(p []byte) (int, error) {
+	n, err := l.w.Write(p)
+	if n > 0 {
+		l.last = p[n-1]
+	}
+	return n, err
+}
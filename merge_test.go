@@ -0,0 +1,201 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const mergeHeader = "Time,Component,Level,Message,ID\n"
+
+// write a csv log file with the default header and a row for each message,
+// gzipped if the name ends in .gz
+func writeLogFile(t *testing.T, dir, name string, messages ...string) {
+	t.Helper()
+	var b strings.Builder
+	b.WriteString(mergeHeader)
+	for _, msg := range messages {
+		b.WriteString("2024-03-10T12:00:00Z,merge,INFO," + msg + ",1\n")
+	}
+	data := []byte(b.String())
+	if strings.HasSuffix(name, ".gz") {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(data)
+		zw.Close()
+		data = buf.Bytes()
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// merge the files in dir from the day of from to the day of to and read
+// back the messages
+func mergedMessages(t *testing.T, dir string, from, to time.Time) (string, []string) {
+	t.Helper()
+	var out bytes.Buffer
+	if err := MergeRange(dir, from, to, &out); err != nil {
+		t.Fatalf("MergeRange: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "merged.csv")
+	if err := os.WriteFile(path, out.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ReadEntries(path)
+	if err != nil {
+		t.Fatalf("merged file can't be read: %v", err)
+	}
+	var messages []string
+	for _, e := range entries {
+		messages = append(messages, e.Message)
+	}
+	return out.String(), messages
+}
+
+func day(d int) time.Time {
+	return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestMergeRange(t *testing.T) {
+	dir := t.TempDir()
+	writeLogFile(t, dir, "log-10-03-2024.csv", "10th")
+	// the 11th is missing
+	writeLogFile(t, dir, "log-12-03-2024.csv", "12th")
+	writeLogFile(t, dir, "log-13-03-2024.csv", "13th")
+	// outside the range
+	writeLogFile(t, dir, "log-09-03-2024.csv", "9th")
+	writeLogFile(t, dir, "log-14-03-2024.csv", "14th")
+
+	// times within the first and last days include the whole days
+	out, messages := mergedMessages(t, dir, day(10).Add(15*time.Hour), day(13).Add(time.Hour))
+	if want := []string{"10th", "12th", "13th"}; !slices.Equal(messages, want) {
+		t.Errorf("merged %q, want %q", messages, want)
+	}
+	if n := strings.Count(out, mergeHeader); n != 1 {
+		t.Errorf("merged file has %d headers, want 1", n)
+	}
+}
+
+// each day's rotated backups come before its current file, oldest first,
+// and compressed files are included
+func TestMergeRangeBackups(t *testing.T) {
+	dir := t.TempDir()
+	writeLogFile(t, dir, "log-10-03-2024.2.csv.gz", "10th oldest")
+	writeLogFile(t, dir, "log-10-03-2024.1.csv", "10th older")
+	writeLogFile(t, dir, "log-10-03-2024.csv", "10th current")
+	writeLogFile(t, dir, "log-11-03-2024.csv.gz", "11th compressed")
+	// not shared csv files
+	writeLogFile(t, dir, "log-api-10-03-2024.csv", "per component")
+	if err := os.WriteFile(filepath.Join(dir, "log-10-03-2024.jsonl"), []byte("{\"message\":\"json\"}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, messages := mergedMessages(t, dir, day(10), day(11))
+	if want := []string{"10th oldest", "10th older", "10th current", "11th compressed"}; !slices.Equal(messages, want) {
+		t.Errorf("merged %q, want %q", messages, want)
+	}
+}
+
+// files written by the logger, with a schema line and an entry spanning
+// lines, merge the same way, keeping the schema line
+func TestMergeRangeLoggerFiles(t *testing.T) {
+	dir := tempLogDir(t)
+	now := day(10).Add(12 * time.Hour)
+	l := NewLogger("merge", "1", WithSilentConsole(), WithSchemaVersion(true),
+		WithClock(func() time.Time { return now }))
+	for range 3 {
+		l.Infoln("first line\nsecond line")
+		now = now.AddDate(0, 0, 1)
+	}
+	l.Close()
+
+	out, messages := mergedMessages(t, dir, day(10), day(12))
+	if len(messages) != 3 || messages[0] != "first line\nsecond line" {
+		t.Errorf("merged %q, want the 3 entries", messages)
+	}
+	if want := fmt.Sprintf("%s%d\n", schemaLinePrefix, SchemaVersion); !strings.HasPrefix(out, want) {
+		t.Errorf("merged file doesn't start with the schema line %q:\n%s", want, out)
+	}
+}
+
+// a file cut off mid row doesn't run into the next one
+func TestMergeRangeTruncatedFile(t *testing.T) {
+	dir := t.TempDir()
+	data := mergeHeader + "2024-03-10T12:00:00Z,merge,INFO,complete,1\n2024-03-10T12:00:01Z,merge,INFO,cut off,1"
+	if err := os.WriteFile(filepath.Join(dir, "log-10-03-2024.csv"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeLogFile(t, dir, "log-11-03-2024.csv", "next day")
+	_, messages := mergedMessages(t, dir, day(10), day(11))
+	if want := []string{"complete", "cut off", "next day"}; !slices.Equal(messages, want) {
+		t.Errorf("merged %q, want %q", messages, want)
+	}
+}
+
+func TestMergeRangeHeaderMismatch(t *testing.T) {
+	dir := t.TempDir()
+	writeLogFile(t, dir, "log-10-03-2024.csv", "10th")
+	if err := os.WriteFile(filepath.Join(dir, "log-11-03-2024.csv"), []byte("Time,Level,Message\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	err := MergeRange(dir, day(10), day(11), &out)
+	if !errors.Is(err, ErrHeaderMismatch) {
+		t.Errorf("MergeRange = %v, want ErrHeaderMismatch", err)
+	}
+	// files before the mismatch are still written
+	if !strings.Contains(out.String(), "10th") {
+		t.Errorf("merged %q, want the 10th's rows", out.String())
+	}
+}
+
+// files with different schema versions can't be merged
+func TestMergeRangeSchemaMismatch(t *testing.T) {
+	dir := t.TempDir()
+	writeLogFile(t, dir, "log-10-03-2024.csv", "10th")
+	data := fmt.Sprintf("%s%d\n%s", schemaLinePrefix, SchemaVersion, mergeHeader)
+	if err := os.WriteFile(filepath.Join(dir, "log-11-03-2024.csv"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := MergeRange(dir, day(10), day(11), &out); !errors.Is(err, ErrHeaderMismatch) {
+		t.Errorf("MergeRange = %v, want ErrHeaderMismatch", err)
+	}
+}
+
+func TestMergeRangeNoFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeLogFile(t, dir, "log-10-03-2024.csv", "10th")
+	var out bytes.Buffer
+	if err := MergeRange(dir, day(11), day(12), &out); err == nil {
+		t.Error("MergeRange with no files in the range succeeded")
+	}
+	if err := MergeRange(filepath.Join(dir, "missing"), day(10), day(10), &out); err == nil {
+		t.Error("MergeRange of a missing directory succeeded")
+	}
+	if out.Len() != 0 {
+		t.Errorf("wrote %q, want nothing", out.String())
+	}
+}
+
+// days are taken in from's time zone, even if to is in another
+func TestMergeRangeTimeZone(t *testing.T) {
+	dir := t.TempDir()
+	writeLogFile(t, dir, "log-10-03-2024.csv", "10th")
+	writeLogFile(t, dir, "log-11-03-2024.csv", "11th")
+	loc := time.FixedZone("UTC+10", 10*3600)
+	from := time.Date(2024, 3, 11, 2, 0, 0, 0, loc)
+	// the 10th in UTC, but the 11th in from's zone
+	to := time.Date(2024, 3, 10, 20, 0, 0, 0, time.UTC)
+	if _, messages := mergedMessages(t, dir, from, to); !slices.Equal(messages, []string{"11th"}) {
+		t.Errorf("merged %q, want only the 11th", messages)
+	}
+}
@@ -0,0 +1,31 @@
+package logger
+
+// Interface is the set of logging methods shared by *Logger and the no-op
+// logger returned by NewNopLogger, so code can accept either one. Fatal
+// isn't included since a no-op logger can't sensibly exit the program.
+type Interface interface {
+	Info(msg string, v ...any)
+	Debug(msg string, v ...any)
+	Warn(msg string, v ...any)
+	Error(msg string, v ...any)
+	Log(level string, msg string)
+	Close() error
+}
+
+var _ Interface = (*Logger)(nil)
+
+// NewNopLogger returns a logger that discards everything logged to it
+// without displaying anything or touching the disk, for use in tests and
+// when logging is disabled.
+func NewNopLogger() Interface {
+	return nopLogger{}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Debug(string, ...any) {}
+func (nopLogger) Warn(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
+func (nopLogger) Log(string, string)   {}
+func (nopLogger) Close() error         { return nil }
@@ -0,0 +1,269 @@
+package logger
+
+import (
+	"io"
+	"math"
+	"os"
+	"time"
+	"unicode/utf8"
+)
+
+// Option configures a Logger at construction.
+type Option func(*Logger)
+
+// WithSanitizeCSV controls whether fields that would be interpreted as
+// formulas by spreadsheet applications are neutralized before being
+// written to the log file. Enabled by default.
+func WithSanitizeCSV(enabled bool) Option {
+	return func(l *Logger) {
+		l.sanitize = enabled
+	}
+}
+
+// WithEscapeNewlines controls whether line breaks in csv fields are
+// replaced with literal \n and \r escapes, so each entry stays on a
+// single line for line oriented tools like grep. JSON log files always
+// keep entries on a single line. Disabled by default.
+func WithEscapeNewlines(enabled bool) Option {
+	return func(l *Logger) {
+		l.escapeNewlines = enabled
+	}
+}
+
+// WithFormat sets the on-disk format of the log file. The console
+// output uses the matching slog handler. Defaults to FormatCSV.
+func WithFormat(format Format) Option {
+	return func(l *Logger) {
+		l.out.format = format
+	}
+}
+
+// WithOutput sets where messages are displayed. Defaults to os.Stdout.
+// This only affects the console output; entries are still written to
+// the log file.
+func WithOutput(w io.Writer) Option {
+	return func(l *Logger) {
+		l.console = w
+	}
+}
+
+// WithSilentConsole disables displaying messages so entries are only
+// written to the log file. See also SetConsole.
+func WithSilentConsole() Option {
+	return func(l *Logger) {
+		l.silent.Store(true)
+	}
+}
+
+// WithFlushInterval buffers entries and flushes them to the log file
+// every d, rather than after every entry. Entries are also flushed
+// whenever the write buffer fills up, and when the logger is closed.
+// Entries that haven't been flushed are lost if the program exits
+// without calling Close.
+func WithFlushInterval(d time.Duration) Option {
+	return func(l *Logger) {
+		l.out.flushInterval = d
+	}
+}
+
+// WithBufferSize buffers up to size bytes of entries before writing them
+// to the log file, rather than writing after every entry, which greatly
+// reduces the number of writes. Entries are written when the buffer fills
+// up, every flush interval if WithFlushInterval is also used, and when
+// the logger is closed. Entries that haven't been written are lost if the
+// program exits without calling Close.
+func WithBufferSize(size int) Option {
+	return func(l *Logger) {
+		l.out.bufSize = size
+	}
+}
+
+// WithAutoFlush controls whether entries are written to the log file after
+// every entry, which is the default. Writing every entry is the biggest
+// cost of logging, so turning it off greatly increases throughput:
+// entries are buffered and only written when the write buffer fills up,
+// when Flush or Close is called, with WithFlushInterval if it's also used,
+// and when an entry at or above the flush level is logged (see
+// WithFlushLevel).
+//
+// The cost is durability. Buffered entries below the flush level are lost
+// if the program crashes or exits without calling Close, and other
+// processes reading the file, such as Follow or tail -f, don't see entries
+// until they're written. Can't be combined with WithSync.
+func WithAutoFlush(enabled bool) Option {
+	return func(l *Logger) {
+		l.out.manualFlush = !enabled
+	}
+}
+
+// WithFlushLevel sets the level at and above which entries are written to
+// the log file immediately, along with everything buffered before them,
+// even when entries are being buffered with WithBufferSize,
+// WithFlushInterval, or WithAutoFlush(false), so the most important
+// entries aren't lost if the program crashes right after logging them.
+// With WithAsync, they're flushed as soon as the background writer
+// reaches them rather than once the queue is empty. This hands entries to
+// the operating system; use WithSync to also wait for the disk. An empty
+// level disables this, and unregistered levels are ignored. Defaults to
+// ERROR.
+func WithFlushLevel(level string) Option {
+	return func(l *Logger) {
+		if level == "" {
+			l.out.flushLevel = math.MaxInt
+		} else if sev, ok := parseLevel(level); ok {
+			l.out.flushLevel = sev
+		}
+	}
+}
+
+// WithSync opens the log file with O_SYNC, so each entry is durably on
+// disk, surviving a crash or power loss, before the logging call returns.
+// This makes every write wait for the disk and is many times slower than
+// the default, so it's best reserved for audit logs. It can't be combined
+// with WithBufferSize, WithFlushInterval, WithAutoFlush(false), or
+// WithAsync, which would defeat it; creating a logger with both fails.
+func WithSync(enabled bool) Option {
+	return func(l *Logger) {
+		l.out.syncWrites = enabled
+	}
+}
+
+// WithFallbackConsole controls what happens when the log directory or file
+// can't be created or written to, such as when LOG_DIR is read only. When
+// enabled, the logger only displays messages, with a warning, instead of
+// failing to be created. Disabled by default.
+func WithFallbackConsole(enabled bool) Option {
+	return func(l *Logger) {
+		l.out.fallbackConsole = enabled
+	}
+}
+
+// WithFileMode sets the permissions used when creating a log file.
+// The mode is applied when the file is created, subject to the process
+// umask; opening an existing log file doesn't change its permissions.
+// Defaults to 0640. Modes are ignored on Windows, where log files are
+// always created writable and access is controlled by the directory.
+func WithFileMode(mode os.FileMode) Option {
+	return func(l *Logger) {
+		l.out.fileMode = mode
+	}
+}
+
+// WithDirMode sets the permissions used when creating the log directory
+// and any missing parents. Like WithFileMode, the mode is applied at
+// creation, subject to the process umask. Defaults to 0755. Like
+// WithFileMode, it's ignored on Windows.
+func WithDirMode(mode os.FileMode) Option {
+	return func(l *Logger) {
+		l.out.dirMode = mode
+	}
+}
+
+// WithPerComponentFile gives each component its own daily log file, named
+// log-<component>-dd-mm-yyyy.csv, instead of sharing log-dd-mm-yyyy.csv
+// with every other logger in the directory. The component is lowercased
+// and characters other than ASCII letters, digits, '-', and '_' are
+// replaced with underscores. Loggers derived with Child or WithComponent
+// keep writing to their parent's file. Disabled by default.
+func WithPerComponentFile(enabled bool) Option {
+	return func(l *Logger) {
+		l.out.perComponent = enabled
+	}
+}
+
+// WithMaxSize rotates the log file once it reaches the given size in
+// bytes. The full file is renamed with an incrementing suffix, such as
+// log-dd-mm-yyyy.1.csv, with .1 always being the most recent, and a new
+// file is started in its place. 0 disables size based rotation, which
+// is the default.
+func WithMaxSize(bytes int64) Option {
+	return func(l *Logger) {
+		l.out.maxSize = bytes
+	}
+}
+
+// WithMaxLines rotates the log file once n entries have been written to
+// it, the same way as WithMaxSize, so files have a bounded number of rows.
+// The header isn't counted. Entries are counted as they're written rather
+// than by reading the file, so entries already in the file when the logger
+// opens it, such as from before the program restarted, aren't counted.
+// Combined with WithMaxSize, the file is rotated when either limit is
+// reached. 0 disables line based rotation, which is the default.
+func WithMaxLines(n int) Option {
+	return func(l *Logger) {
+		l.out.maxLines = n
+	}
+}
+
+// WithMaxBackups sets how many rotated files are kept for each day when
+// size or line based rotation is enabled. The oldest are removed first.
+// 0 keeps all of them, which is the default.
+func WithMaxBackups(n int) Option {
+	return func(l *Logger) {
+		l.out.maxBackups = n
+	}
+}
+
+// WithMaxAge removes log files older than d from the log directory when
+// the logger is created and whenever it rolls over to a new day. Files
+// are dated by the date in their name rather than their modification
+// time, and only files matching the log file naming format are removed.
+// Only the logger's own files are removed: the shared files, or its
+// component's files with WithPerComponentFile, so a component's retention
+// doesn't remove other components' logs. 0 keeps log files forever, which
+// is the default.
+func WithMaxAge(d time.Duration) Option {
+	return func(l *Logger) {
+		l.out.maxAge = d
+	}
+}
+
+// WithCompress gzips log files once the logger rolls over to a new day,
+// replacing log-dd-mm-yyyy.csv with log-dd-mm-yyyy.csv.gz. Rotated backups
+// for the day are compressed as well. Compression happens in the
+// background, and Close waits for it to finish. Disabled by default.
+func WithCompress(enabled bool) Option {
+	return func(l *Logger) {
+		l.out.compressOld = enabled
+	}
+}
+
+// WithTimeZone sets the time zone used for entry timestamps and for the
+// date in the log file name, so entries always land in the file for the
+// day they were logged. Defaults to UTC.
+func WithTimeZone(loc *time.Location) Option {
+	return func(l *Logger) {
+		if loc != nil {
+			l.out.loc = loc
+		}
+	}
+}
+
+// WithClock sets the function used to get the current time, for entry
+// timestamps, daily rollover, and retention. Mostly useful for testing.
+// Defaults to time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(l *Logger) {
+		if now != nil {
+			l.out.now = now
+		}
+	}
+}
+
+// WithDelimiter sets the field delimiter for csv log files, such as '\t'
+// for tab separated values. Tab delimited files use the .tsv extension.
+// Invalid delimiters, such as quotes and newlines, are ignored. Defaults
+// to ','. Files written with a custom delimiter should be read using
+// ReadDelimiter with the same delimiter.
+func WithDelimiter(r rune) Option {
+	return func(l *Logger) {
+		if validDelimiter(r) {
+			l.out.comma = r
+		}
+	}
+}
+
+// reports whether r can be used as a csv field delimiter
+func validDelimiter(r rune) bool {
+	return r != 0 && r != '"' && r != '\r' && r != '\n' && utf8.ValidRune(r) && r != utf8.RuneError
+}
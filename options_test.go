@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSyncWrites(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("sync", "1", WithSilentConsole(), WithSync(true))
+	defer l.Close()
+	if !l.out.syncWrites {
+		t.Fatal("WithSync wasn't applied")
+	}
+	l.Info("durable")
+	// written without a Flush
+	entries, err := ReadEntries(l.FilePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Message != "durable" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestSyncRejectsBufferedAndAsyncWrites(t *testing.T) {
+	tempLogDir(t)
+	for name, opt := range map[string]Option{
+		"WithBufferSize":    WithBufferSize(4096),
+		"WithFlushInterval": WithFlushInterval(time.Second),
+		"WithAutoFlush":     WithAutoFlush(false),
+		"WithAsync":         WithAsync(16),
+	} {
+		if _, err := NewLoggerE("sync", "1", WithSync(true), opt); err == nil {
+			t.Errorf("WithSync with %s succeeded, want an error", name)
+		}
+	}
+}
+
+// the entries on disk, without flushing
+func onDisk(t *testing.T, path string) []Entry {
+	t.Helper()
+	entries, err := ReadEntries(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func TestFlushLevel(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("flush", "1", WithSilentConsole(), WithBufferSize(1<<20), WithFlushInterval(time.Hour))
+	defer l.Close()
+	for range 5 {
+		l.Infoln("buffered")
+	}
+	if entries := onDisk(t, l.FilePath()); len(entries) != 0 {
+		t.Fatalf("%d entries on disk before the error, want them buffered", len(entries))
+	}
+	l.Errorln("critical")
+	entries := onDisk(t, l.FilePath())
+	if len(entries) != 6 || entries[5].Message != "critical" {
+		t.Errorf("%d entries on disk after the error, want the 5 before it and the error", len(entries))
+	}
+}
+
+func TestFlushLevelAsync(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("flush", "1", WithSilentConsole(), WithAsync(64), WithAutoFlush(false), WithFlushLevel(WARN))
+	defer l.Close()
+	for range 5 {
+		l.Infoln("buffered")
+	}
+	l.Warnln("critical")
+	// the writer flushes once it reaches the warning
+	deadline := time.Now().Add(5 * time.Second)
+	for len(onDisk(t, l.FilePath())) != 6 {
+		if time.Now().After(deadline) {
+			t.Fatal("entries before the warning weren't flushed with it")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFlushLevelDisabled(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("flush", "1", WithSilentConsole(), WithAutoFlush(false), WithFlushLevel(""))
+	defer l.Close()
+	l.Infoln("buffered")
+	l.Errorln("also buffered")
+	if entries := onDisk(t, l.FilePath()); len(entries) != 0 {
+		t.Errorf("%d entries on disk, want none flushed", len(entries))
+	}
+	if entries := readLog(t, l); len(entries) != 2 {
+		t.Errorf("%d entries after Flush, want 2", len(entries))
+	}
+}
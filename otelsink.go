@@ -0,0 +1,175 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// NewOTelSink returns a sink that exports entries to an OpenTelemetry
+// collector as OTLP log records, using the OTLP/HTTP JSON encoding, so
+// no OpenTelemetry dependencies are needed. endpoint is the collector's
+// logs endpoint, such as "http://localhost:4318/v1/logs", and service is
+// recorded as the service.name resource attribute.
+//
+// Each entry's message is the record's body, and its level is mapped to a
+// severity number (DEBUG to 5, INFO to 9, WARN to 13, ERROR to 17, and
+// FATAL to 21, with registered levels placed by their severity) with the
+// level as the severity text. The component, ID, source, and fields are
+// recorded as attributes. Batching, retries, and headers, such as for
+// authentication, are configured with the same options as NewHTTPSink.
+// Like other sinks, exporting happens in the background, and entries that
+// can't be exported are dropped and counted but are still written to the
+// log file.
+//
+// This deliberately doesn't take an exporter from the OpenTelemetry logs
+// SDK, which would make every user of this package depend on the SDK.
+// Programs already using the SDK can point the sink at a collector, or
+// export entries themselves with a custom Sink.
+func NewOTelSink(endpoint string, service string, opts ...HTTPOption) *HTTPSink {
+	encode := func(batch []Entry) ([]byte, error) {
+		return encodeOTLPLogs(service, batch)
+	}
+	return NewHTTPSink(endpoint, append([]HTTPOption{func(s *HTTPSink) { s.encode = encode }}, opts...)...)
+}
+
+// the parts of an OTLP ExportLogsServiceRequest used to export entries.
+// see https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+type (
+	otlpLogsRequest struct {
+		ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+	}
+	otlpResourceLogs struct {
+		Resource  otlpResource    `json:"resource"`
+		ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+	otlpScopeLogs struct {
+		Scope      otlpScope       `json:"scope"`
+		LogRecords []otlpLogRecord `json:"logRecords"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpLogRecord struct {
+		TimeUnixNano   string          `json:"timeUnixNano"`
+		SeverityNumber int             `json:"severityNumber,omitempty"`
+		SeverityText   string          `json:"severityText,omitempty"`
+		Body           otlpValue       `json:"body"`
+		Attributes     []otlpAttribute `json:"attributes,omitempty"`
+	}
+	otlpAttribute struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"` // int64 values are strings in OTLP JSON
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+// encode a batch of entries as an OTLP logs request
+func encodeOTLPLogs(service string, batch []Entry) ([]byte, error) {
+	records := make([]otlpLogRecord, len(batch))
+	for i, e := range batch {
+		records[i] = toOTLPRecord(e)
+	}
+	return json.Marshal(otlpLogsRequest{
+		ResourceLogs: []otlpResourceLogs{{
+			Resource: otlpResource{Attributes: []otlpAttribute{otlpString("service.name", service)}},
+			ScopeLogs: []otlpScopeLogs{{
+				Scope:      otlpScope{Name: "github.com/null-create/logger"},
+				LogRecords: records,
+			}},
+		}},
+	})
+}
+
+// convert an entry to an OTLP log record
+func toOTLPRecord(e Entry) otlpLogRecord {
+	attrs := []otlpAttribute{otlpString("component", e.Component)}
+	if e.ID != "" {
+		attrs = append(attrs, otlpString("id", e.ID))
+	}
+	if e.TaskID != "" {
+		attrs = append(attrs, otlpString("task_id", e.TaskID))
+	}
+	if e.Source != "" {
+		attrs = append(attrs, otlpString("source", e.Source))
+	}
+	for _, k := range slices.Sorted(maps.Keys(e.Fields)) {
+		attrs = append(attrs, otlpAttribute{Key: k, Value: toOTLPValue(e.Fields[k])})
+	}
+	return otlpLogRecord{
+		TimeUnixNano:   strconv.FormatInt(e.Time.UnixNano(), 10),
+		SeverityNumber: otlpSeverity(e.Level),
+		SeverityText:   e.Level,
+		Body:           otlpValue{StringValue: &e.Message},
+		Attributes:     attrs,
+	}
+}
+
+// map a level to an OTLP severity number. the built-in levels match slog's,
+// which are 9 below OTLP's (INFO is 0 in slog and 9 in OTLP). unregistered
+// levels are left unspecified.
+func otlpSeverity(level string) int {
+	sev, ok := parseLevel(level)
+	if !ok {
+		return 0
+	}
+	return min(max(sev+9, 1), 24)
+}
+
+func otlpString(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{StringValue: &value}}
+}
+
+// convert a field value to an OTLP value. types without an OTLP
+// equivalent are encoded as JSON strings.
+func toOTLPValue(v any) otlpValue {
+	switch v := v.(type) {
+	case string:
+		return otlpValue{StringValue: &v}
+	case bool:
+		return otlpValue{BoolValue: &v}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32:
+		s := fmt.Sprint(v)
+		return otlpValue{IntValue: &s}
+	case uint64:
+		// OTLP integers are signed, so larger values are strings
+		s := strconv.FormatUint(v, 10)
+		if v > math.MaxInt64 {
+			return otlpValue{StringValue: &s}
+		}
+		return otlpValue{IntValue: &s}
+	case float32:
+		return toOTLPValue(float64(v))
+	case float64:
+		// JSON has no NaN or infinity, so they're strings like in JSON output
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			s := fmt.Sprint(v)
+			return otlpValue{StringValue: &s}
+		}
+		return otlpValue{DoubleValue: &v}
+	case error:
+		s := v.Error()
+		return otlpValue{StringValue: &s}
+	case fmt.Stringer:
+		s := v.String()
+		return otlpValue{StringValue: &s}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		s := fmt.Sprint(v)
+		return otlpValue{StringValue: &s}
+	}
+	s := string(b)
+	return otlpValue{StringValue: &s}
+}
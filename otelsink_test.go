@@ -0,0 +1,185 @@
+package logger
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// an OTLP collector recording the log records posted to it
+type otlpCollector struct {
+	mu       sync.Mutex
+	requests []map[string]any
+}
+
+func (c *otlpCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, req)
+}
+
+// the first resource's service.name and its log records
+func (c *otlpCollector) records(t *testing.T) (service string, records []map[string]any) {
+	t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, req := range c.requests {
+		rl := req["resourceLogs"].([]any)[0].(map[string]any)
+		attr := rl["resource"].(map[string]any)["attributes"].([]any)[0].(map[string]any)
+		if attr["key"] != "service.name" {
+			t.Errorf("resource attribute = %v, want service.name", attr)
+		}
+		service = attr["value"].(map[string]any)["stringValue"].(string)
+		for _, r := range rl["scopeLogs"].([]any)[0].(map[string]any)["logRecords"].([]any) {
+			records = append(records, r.(map[string]any))
+		}
+	}
+	return service, records
+}
+
+// the record's attributes as their OTLP JSON values
+func otlpAttributes(record map[string]any) map[string]map[string]any {
+	attrs := make(map[string]map[string]any)
+	for _, a := range record["attributes"].([]any) {
+		a := a.(map[string]any)
+		attrs[a["key"].(string)] = a["value"].(map[string]any)
+	}
+	return attrs
+}
+
+func TestOTelSinkRecordMapping(t *testing.T) {
+	c := &otlpCollector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	now := time.Date(2024, 3, 10, 12, 0, 0, 5, time.UTC)
+	sink := NewOTelSink(srv.URL, "checkout", HTTPBatchSize(10))
+	l, _ := NewBufferLogger("payments", "42", WithSilentConsole(), WithSinks(sink),
+		WithClock(func() time.Time { return now }))
+	l.WithFields(map[string]any{"attempt": 3, "ok": false, "ratio": 0.5, "user": "ada"}).Warn("card declined")
+	l.Error("gateway down")
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	service, records := c.records(t)
+	if service != "checkout" {
+		t.Errorf("service.name = %q, want checkout", service)
+	}
+	if len(records) != 2 {
+		t.Fatalf("exported %d records, want 2", len(records))
+	}
+	warn := records[0]
+	if warn["timeUnixNano"] != "1710072000000000005" {
+		t.Errorf("timeUnixNano = %v", warn["timeUnixNano"])
+	}
+	if warn["severityNumber"] != 13.0 || warn["severityText"] != WARN {
+		t.Errorf("severity = %v %v, want 13 WARN", warn["severityNumber"], warn["severityText"])
+	}
+	if body := warn["body"].(map[string]any); body["stringValue"] != "card declined" {
+		t.Errorf("body = %v", body)
+	}
+	attrs := otlpAttributes(warn)
+	for key, want := range map[string]map[string]any{
+		"component": {"stringValue": "payments"},
+		"id":        {"stringValue": "42"},
+		"attempt":   {"intValue": "3"},
+		"ok":        {"boolValue": false},
+		"ratio":     {"doubleValue": 0.5},
+		"user":      {"stringValue": "ada"},
+	} {
+		got := attrs[key]
+		if len(got) != 1 {
+			t.Errorf("attribute %s = %v, want %v", key, got, want)
+			continue
+		}
+		for k, v := range want {
+			if got[k] != v {
+				t.Errorf("attribute %s = %v, want %v", key, got, want)
+			}
+		}
+	}
+	if records[1]["severityNumber"] != 17.0 {
+		t.Errorf("ERROR severity = %v, want 17", records[1]["severityNumber"])
+	}
+}
+
+func TestOTLPSeverity(t *testing.T) {
+	if err := RegisterLevel("NOTICE", 2); err != nil {
+		t.Fatal(err)
+	}
+	for level, want := range map[string]int{
+		DEBUG:     5,
+		INFO:      9,
+		WARN:      13,
+		ERROR:     17,
+		FATAL:     21,
+		"NOTICE":  11,
+		"UNKNOWN": 0,
+	} {
+		if got := otlpSeverity(level); got != want {
+			t.Errorf("otlpSeverity(%s) = %d, want %d", level, got, want)
+		}
+	}
+}
+
+func TestOTLPValueSpecialNumbers(t *testing.T) {
+	for _, tt := range []struct {
+		v    any
+		want string
+	}{
+		{math.NaN(), `{"stringValue":"NaN"}`},
+		{math.Inf(1), `{"stringValue":"+Inf"}`},
+		{float32(math.Inf(-1)), `{"stringValue":"-Inf"}`},
+		{uint64(7), `{"intValue":"7"}`},
+		{uint64(math.MaxUint64), `{"stringValue":"18446744073709551615"}`},
+	} {
+		b, err := json.Marshal(toOTLPValue(tt.v))
+		if err != nil {
+			t.Errorf("encoding %v: %v", tt.v, err)
+			continue
+		}
+		if string(b) != tt.want {
+			t.Errorf("toOTLPValue(%v) = %s, want %s", tt.v, b, tt.want)
+		}
+	}
+	// a NaN field doesn't stop the rest of the batch from being encoded
+	e := Entry{Level: INFO, Message: "m", Fields: map[string]any{"ratio": math.NaN()}}
+	if _, err := encodeOTLPLogs("svc", []Entry{e}); err != nil {
+		t.Errorf("encodeOTLPLogs with a NaN field: %v", err)
+	}
+}
+
+// a failing collector doesn't block logging or keep entries out of the
+// log file
+func TestOTelSinkExportFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	tempLogDir(t)
+	sink := NewOTelSink(srv.URL, "checkout", HTTPBatchSize(1), HTTPRetries(0, 0))
+	l := NewLogger("otel", "1", WithSilentConsole(), WithSinks(sink))
+	for range 5 {
+		l.Infoln("entry")
+	}
+	if entries := readLog(t, l); len(entries) != 5 {
+		t.Errorf("log file has %d entries, want 5", len(entries))
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := l.DropStats()[DropSink]; got != 5 {
+		t.Errorf("DropStats()[DropSink] = %d, want 5", got)
+	}
+}
@@ -0,0 +1,344 @@
+package logger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// output is the log file a logger writes to. it is shared between
+// a logger, any loggers derived from it, and any other loggers writing
+// to the same file, so they serialize their writes through a single
+// file handle and csv writer.
+type output struct {
+	mu              sync.Mutex            // lock so loggers don't over write each other
+	dir             string                // directory the log files are placed in
+	path            string                // absolute path to the csv log file
+	fixedPath       bool                  // whether path was set with SetOutputFile, so isn't rolled over daily
+	hostname        bool                  // whether the host name is written with each entry
+	host            string                // host name written with entries
+	lowDisk         uint64                // free space below which a warning is written. 0 disables the check
+	nextDiskCheck   time.Time             // when free space should be checked next
+	lowDiskWarned   bool                  // whether the low disk space warning has been written
+	lowDiskWarning  atomic.Pointer[Entry] // low disk space warning waiting to be written once o.mu is released
+	perComponent    bool                  // whether the component is included in log file names
+	fileComponent   string                // component included in log file names, if any
+	nextDay         time.Time             // when the current log file should be rolled over
+	file            *os.File              // open handle to the csv log file, nil if writing to dest
+	dest            io.Writer             // writer entries are written to instead of a log file, if set
+	buf             *bufio.Writer         // buffered writer on top of file
+	csvWriter       *csv.Writer           // csv writer instance, writes to buf
+	closed          bool                  // whether the log file has been closed
+	format          Format                // on-disk format of the log file
+	formatter       rowFormatter          // formats entries instead of the format, if set
+	loc             *time.Location        // time zone for timestamps and file dates
+	now             func() time.Time      // returns the current time
+	fileMode        os.FileMode           // permissions for created log files
+	dirMode         os.FileMode           // permissions for created log directories
+	flushInterval   time.Duration         // how often buffered entries are flushed. 0 flushes every entry
+	bufSize         int                   // size of the write buffer. if set, entries are only flushed once it fills
+	manualFlush     bool                  // whether flushing after every entry is disabled
+	flushLevel      int                   // severity at which entries are flushed immediately even when buffering
+	syncWrites      bool                  // whether to open the log file with O_SYNC
+	writeTimeout    time.Duration         // how long a write may take before it's abandoned. 0 waits forever
+	timeoutWriter   *timeoutWriter        // writes to file with a timeout, if enabled
+	fallbackConsole bool                  // whether to only display messages if the log file can't be opened
+	stop            chan struct{}         // closed to stop the background flusher
+	stopOnce        sync.Once             // guards closing stop
+	flusherDone     sync.WaitGroup        // waits for the background flusher to exit
+	err             error                 // most recent error writing to the log file
+	size            int64                 // bytes written to the current log file
+	maxSize         int64                 // size at which the log file is rotated. 0 disables rotation
+	maxLines        int                   // number of entries after which the log file is rotated. 0 disables rotation
+	lines           int                   // entries written to the log file since it was opened
+	maxBackups      int                   // number of rotated files to keep. 0 keeps all of them
+	maxAge          time.Duration         // how long log files are kept. 0 keeps them forever
+	compressOld     bool                  // whether to gzip log files after rolling over
+	streamGzip      bool                  // whether to gzip the active log file as it's written
+	gz              *gzip.Writer          // compresses entries written to the log file, if streamGzip
+	compressing     sync.WaitGroup        // waits for background compression to finish
+	addSource       bool                  // whether to write the caller's source location
+	sequence        bool                  // whether to number entries
+	seq             atomic.Uint64         // sequence number of the last numbered entry
+	migrateHeader   bool                  // whether to move aside existing files with a different header
+	schemaVersion   bool                  // whether to write the schema version before the header
+	extraColumn     bool                  // whether to always write fields in an Extra column
+	columns         []Column              // columns written to csv log files
+	timeFormat      string                // layout for entry timestamps
+	hookMu          sync.RWMutex          // guards hooks
+	hooks           []hook                // called after entries are written
+	limit           *limiter              // rate limit and sampling, if configured
+	dedup           *dedup                // collapses repeated entries, if configured
+	throttle        throttle              // writes each unique message at most once per ttl
+	asyncSize       int                   // size of the async buffer. 0 writes synchronously
+	asyncPolicy     AsyncPolicy           // what to do when the async buffer is full
+	async           *asyncWriter          // writes entries in the background, if enabled
+	key             string                // key in the shared outputs registry
+	refs            int                   // loggers sharing this output, guarded by outputsMu
+	fileLock        bool                  // whether to lock the log file while writing to it
+	locked          bool                  // whether the file lock is held
+	counts          levelCounts           // entries logged at each level
+	drops           dropCounts            // entries dropped for each reason
+	panics          atomic.Uint64         // panics recovered in the logging path
+	comma           rune                  // field delimiter for csv log files
+}
+
+// file extension for log files. tab delimited csv files use .tsv, files
+// written with a custom formatter use .log, and compressed files have .gz
+// added
+func (o *output) ext() string {
+	ext := o.format.ext()
+	if o.formatter != nil {
+		ext = ".log"
+	} else if o.format == FormatCSV && o.comma == '\t' {
+		ext = ".tsv"
+	}
+	if o.streamGzip {
+		ext += ".gz"
+	}
+	return ext
+}
+
+// column names for csv log files created by this output
+func (o *output) header() []string {
+	header := columnNames(o.columns)
+	if o.sequence {
+		header = append(header, seqColumn)
+	}
+	if o.addSource {
+		header = append(header, sourceColumn)
+	}
+	if o.extraColumn {
+		header = append(header, extraColumn)
+	}
+	return header
+}
+
+// counts the bytes written to the log file so its size can
+// be tracked without calling stat on every write.
+type countingWriter struct {
+	w    io.Writer
+	size *int64
+}
+
+func (c countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	*c.size += int64(n)
+	return n, err
+}
+
+// set the file being written to. any pending entries for the
+// previous file must already be flushed.
+// must be called while holding o.mu.
+func (o *output) setFile(file *os.File) {
+	o.file = file
+	o.size = 0
+	o.lines = 0
+	if info, err := file.Stat(); err == nil {
+		o.size = info.Size()
+	}
+	o.setWriter(o.fileWriter(file))
+}
+
+// set the writer entries are written to, such as the log file.
+// must be called while holding o.mu.
+func (o *output) setWriter(w io.Writer) {
+	w = countingWriter{w: w, size: &o.size}
+	if o.streamGzip {
+		o.gz = gzip.NewWriter(w)
+		w = o.gz
+	}
+	o.buf = bufio.NewWriterSize(w, o.bufSize)
+	o.csvWriter = csv.NewWriter(o.buf)
+	o.csvWriter.Comma = o.comma
+}
+
+// write pending entries to the log file.
+// must be called while holding o.mu.
+func (o *output) flush() (err error) {
+	defer func() {
+		// unlocking would touch a file whose write has hung
+		if !errors.Is(err, ErrWriteTimeout) {
+			o.unlock()
+		}
+	}()
+	o.csvWriter.Flush()
+	if err := o.csvWriter.Error(); err != nil {
+		return err
+	}
+	if err := o.buf.Flush(); err != nil {
+		return err
+	}
+	if o.gz != nil {
+		return o.gz.Flush()
+	}
+	return nil
+}
+
+// record a write error and reset the buffered writers, which otherwise
+// refuse all further writes once an error has occurred. entries that
+// were still buffered are discarded.
+// must be called while holding o.mu.
+func (o *output) fail(err error) {
+	o.err = err
+	switch {
+	case o.file == nil:
+		o.setWriter(o.dest)
+	case errors.Is(err, ErrWriteTimeout):
+		// the file may be on a hung filesystem, so it isn't touched: the
+		// tracked size is kept rather than calling stat, and the file lock
+		// is left to be released when the file is closed.
+		o.locked = false
+		o.setWriter(o.fileWriter(o.file))
+	default:
+		o.unlock()
+		o.setFile(o.file)
+	}
+}
+
+// reports whether entries at level are severe enough to be flushed
+// immediately even if entries are being buffered
+func (o *output) critical(level string) bool {
+	sev, ok := parseLevel(level)
+	return ok && sev >= o.flushLevel
+}
+
+// write any queued and pending entries to the log file.
+func (o *output) sync() error {
+	if o.async != nil {
+		o.async.wait()
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return nil
+	}
+	o.flushRepeats()
+	if err := o.flush(); err != nil {
+		o.fail(err)
+		return fmt.Errorf("failed to flush log file: %w", err)
+	}
+	return nil
+}
+
+// write a csv record to the log file's write buffer.
+// must be called while holding o.mu.
+func (o *output) writeCSV(record []string) error {
+	return o.csvWriter.Write(record)
+}
+
+// write an entry formatted with the custom formatter as a single line
+// to the log file's write buffer.
+// must be called while holding o.mu.
+func (o *output) writeFormatted(e Entry) error {
+	if _, err := o.buf.Write(o.formatter(e)); err != nil {
+		return err
+	}
+	return o.buf.WriteByte('\n')
+}
+
+// write a json entry as a single line to the log file's write buffer.
+// must be called while holding o.mu.
+func (o *output) writeJSON(entry jsonEntry) error {
+	return json.NewEncoder(o.buf).Encode(entry)
+}
+
+// reports whether entries are buffered rather than flushed after every entry
+func (o *output) buffered() bool {
+	return o.flushInterval > 0 || o.bufSize > 0 || o.manualFlush
+}
+
+// flush written entries to the log file unless entries are being
+// buffered or flushed periodically and force is false. errors are
+// recorded rather than returned. returns false if the flush failed.
+// must be called while holding o.mu.
+func (o *output) autoFlush(force bool) bool {
+	if !force && o.buffered() {
+		return true
+	}
+	if err := o.flush(); err != nil {
+		o.fail(err)
+		log.Printf("failed to flush log file: %v", err)
+		return false
+	}
+	return true
+}
+
+// start flushing buffered entries every flushInterval. entries are also
+// written whenever the write buffer fills up between flushes.
+func (o *output) startFlusher() {
+	o.stop = make(chan struct{})
+	o.flusherDone.Add(1)
+	go func() {
+		defer o.flusherDone.Done()
+		ticker := time.NewTicker(o.flushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-o.stop:
+				return
+			case <-ticker.C:
+				o.mu.Lock()
+				if !o.closed {
+					if err := o.flush(); err != nil {
+						o.fail(err)
+						log.Printf("failed to flush log file: %v", err)
+					}
+				}
+				o.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// stop the background flusher, if running, and wait for it to exit.
+func (o *output) stopFlusher() {
+	if o.stop == nil {
+		return
+	}
+	o.stopOnce.Do(func() { close(o.stop) })
+	o.flusherDone.Wait()
+}
+
+// write queued and pending entries and close the log file,
+// waiting for any background compression to finish. closing more than once is a no-op.
+func (o *output) close() error {
+	if o.limit != nil {
+		o.limit.stopSummaries()
+	}
+	if o.async != nil {
+		o.async.close()
+	}
+	o.stopFlusher()
+	defer o.compressing.Wait()
+
+	o.mu.Lock()
+	if o.closed {
+		o.mu.Unlock()
+		return nil
+	}
+	o.closed = true
+
+	o.flushRepeats()
+	err := o.flush()
+	if err != nil {
+		err = fmt.Errorf("failed to flush log file: %w", err)
+	}
+	if cerr := o.closeFile(); err == nil {
+		err = cerr
+	}
+	if o.timeoutWriter != nil {
+		o.timeoutWriter.stop()
+	}
+	o.mu.Unlock()
+	return err
+}
@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHookPanicRecovered(t *testing.T) {
+	l, buf := NewBufferLogger("panic", "1", WithSilentConsole())
+	var calls []string
+	l.OnLevel(ERROR, func(Entry) { panic("misbehaving hook") })
+	l.OnLevel(ERROR, func(e Entry) { calls = append(calls, e.Message) })
+
+	l.Error("first")
+	l.Error("second")
+	l.Info("still logging")
+	l.Close()
+
+	if len(calls) != 2 {
+		t.Errorf("later hook called %d times, want 2", len(calls))
+	}
+	for _, msg := range []string{"first", "second", "still logging"} {
+		if !strings.Contains(buf.String(), msg) {
+			t.Errorf("log is missing %q:\n%s", msg, buf.String())
+		}
+	}
+}
+
+func TestColumnPanicRecovered(t *testing.T) {
+	bad := Column{Name: "Bad", Value: func(e Entry) string {
+		if e.Message == "boom" {
+			panic("bad column")
+		}
+		return "ok"
+	}}
+	l, buf := NewBufferLogger("panic", "1", WithSilentConsole(), WithColumns(append(DefaultColumns(), bad)...))
+	l.Info("boom")
+	l.Info("after the panic")
+	l.Close()
+
+	if n := l.out.panics.Load(); n != 1 {
+		t.Errorf("recovered %d panics, want 1", n)
+	}
+	if !strings.Contains(buf.String(), "after the panic") {
+		t.Errorf("logging stopped after a panic:\n%s", buf.String())
+	}
+}
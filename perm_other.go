@@ -0,0 +1,13 @@
+//go:build !windows
+
+package logger
+
+import "os"
+
+// permissions to create log files and directories with
+func filePerm(mode os.FileMode) os.FileMode { return mode }
+
+func dirPerm(mode os.FileMode) os.FileMode { return mode }
+
+// key identifying a path, for paths that are the same file
+func pathKey(path string) string { return path }
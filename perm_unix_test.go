@@ -0,0 +1,89 @@
+//go:build unix
+
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// set the process umask for the test
+func setUmask(t *testing.T, mask int) {
+	old := syscall.Umask(mask)
+	t.Cleanup(func() { syscall.Umask(old) })
+}
+
+// the permission bits of a file
+func perm(t *testing.T, path string) os.FileMode {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Mode().Perm()
+}
+
+func TestDefaultModes(t *testing.T) {
+	setUmask(t, 0022)
+	dir := filepath.Join(t.TempDir(), "logs", "app")
+	t.Setenv("LOG_DIR", dir)
+	l := NewLogger("perm", "1", WithSilentConsole())
+	defer l.Close()
+
+	// created directories must be traversable
+	for _, d := range []string{dir, filepath.Dir(dir)} {
+		if got := perm(t, d); got != 0755 {
+			t.Errorf("%s has mode %v, want 0755", d, got)
+		}
+	}
+	if got := perm(t, l.FilePath()); got != 0640 {
+		t.Errorf("log file has mode %v, want 0640", got)
+	}
+	// the directory can be listed and files created in it
+	if _, err := os.ReadDir(dir); err != nil {
+		t.Errorf("can't list the log directory: %v", err)
+	}
+}
+
+func TestConfiguredModes(t *testing.T) {
+	for _, tc := range []struct {
+		umask             int
+		dirMode, fileMode os.FileMode
+		wantDir, wantFile os.FileMode
+	}{
+		{0, 0750, 0600, 0750, 0600},
+		{0, 0775, 0664, 0775, 0664},
+		// the umask still applies
+		{0027, 0777, 0666, 0750, 0640},
+	} {
+		setUmask(t, tc.umask)
+		dir := filepath.Join(t.TempDir(), "logs")
+		t.Setenv("LOG_DIR", dir)
+		l := NewLogger("perm", "1", WithSilentConsole(), WithDirMode(tc.dirMode), WithFileMode(tc.fileMode))
+		if got := perm(t, dir); got != tc.wantDir {
+			t.Errorf("umask %03o, WithDirMode(%v): directory has mode %v, want %v", tc.umask, tc.dirMode, got, tc.wantDir)
+		}
+		if got := perm(t, l.FilePath()); got != tc.wantFile {
+			t.Errorf("umask %03o, WithFileMode(%v): file has mode %v, want %v", tc.umask, tc.fileMode, got, tc.wantFile)
+		}
+		l.Close()
+	}
+}
+
+func TestFileModeOnlyAppliesAtCreation(t *testing.T) {
+	setUmask(t, 0)
+	tempLogDir(t)
+	l := NewLogger("perm", "1", WithSilentConsole())
+	path := l.FilePath()
+	l.Close()
+	if err := os.Chmod(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+	l = NewLogger("perm", "1", WithSilentConsole(), WithFileMode(0644))
+	defer l.Close()
+	if got := perm(t, path); got != 0600 {
+		t.Errorf("existing file has mode %v, want it left at 0600", got)
+	}
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package logger
+
+import (
+	"os"
+	"strings"
+)
+
+// windows only uses the owner write bit of a file mode, creating a read
+// only file without it, which couldn't be reopened for appending, and
+// ignores directory modes. so modes are ignored and log files are always
+// created writable, with access controlled by the directory's ACLs.
+func filePerm(os.FileMode) os.FileMode { return 0666 }
+
+func dirPerm(os.FileMode) os.FileMode { return 0777 }
+
+// paths on windows are case insensitive, so C:\Logs and c:\logs are
+// the same directory.
+func pathKey(path string) string {
+	return strings.ToLower(path)
+}
@@ -0,0 +1,53 @@
+package logger
+
+import "time"
+
+// Query selects entries when reading a log file with FilterEntries.
+// Zero valued filters match every entry.
+type Query struct {
+	MinLevel  string    // only entries at or above this level. entries with unknown levels are excluded
+	Component string    // only entries from this component
+	Since     time.Time // only entries at or after this time
+	Until     time.Time // only entries before this time
+	IDEquals  string    // only entries with this ID
+}
+
+// reports whether the entry satisfies every filter in the query
+func (q Query) match(e Entry) bool {
+	if q.MinLevel != "" {
+		min, ok := parseLevel(q.MinLevel)
+		if !ok {
+			return false
+		}
+		sev, ok := parseLevel(e.Level)
+		if !ok || sev < min {
+			return false
+		}
+	}
+	if q.Component != "" && e.Component != q.Component {
+		return false
+	}
+	if !q.Since.IsZero() && e.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !e.Time.Before(q.Until) {
+		return false
+	}
+	if q.IDEquals != "" && e.ID != q.IDEquals {
+		return false
+	}
+	return true
+}
+
+// FilterEntries reads the entries matching q from a log file. The file
+// is streamed so only the matching entries are held in memory.
+func FilterEntries(path string, q Query, opts ...ReadOption) ([]Entry, error) {
+	var entries []Entry
+	err := ReadEntriesFunc(path, func(e Entry) bool {
+		if q.match(e) {
+			entries = append(entries, e)
+		}
+		return true
+	}, opts...)
+	return entries, err
+}
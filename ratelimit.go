@@ -0,0 +1,168 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// limiter drops entries beyond a rate limit and/or samples entries,
+// counting what was dropped so it can be summarized. it only uses
+// atomics so it doesn't contend with the output's lock.
+type limiter struct {
+	maxPerSecond int64         // entries allowed per second. 0 is unlimited
+	sampleEvery  uint64        // record 1 in every sampleEvery entries. 0 or 1 records all
+	window       atomic.Uint64 // current one second window in unix seconds, in the upper 32 bits, and the entries allowed in it
+	seen         atomic.Uint64 // entries seen, for sampling
+	dropped      atomic.Uint64 // entries dropped since the last summary
+	since        atomic.Int64  // when the last summary was written, in unix nanoseconds
+	stop         chan struct{} // closed to stop writing summaries
+	stopOnce     sync.Once     // guards closing stop
+	done         sync.WaitGroup
+}
+
+// how often a summary of dropped entries is written if no entries are
+// logged to trigger one. a variable so tests can shorten it.
+var summaryInterval = time.Second
+
+// WithRateLimit limits the logger to maxPerSecond entries per second,
+// dropping the rest. Dropped entries are counted and summarized with a
+// WARN entry, such as "suppressed 4213 messages in last 1s", once the
+// second has passed, either when the next entry is logged or in the
+// background shortly after. The limit is shared with derived loggers.
+// LogBatch is not rate limited.
+func WithRateLimit(maxPerSecond int) Option {
+	return func(l *Logger) {
+		l.out.limiter().maxPerSecond = int64(maxPerSecond)
+	}
+}
+
+// WithSampling records only 1 in every n entries, dropping the rest.
+// Dropped entries are counted and summarized the same way as WithRateLimit.
+func WithSampling(n int) Option {
+	return func(l *Logger) {
+		if n > 1 {
+			l.out.limiter().sampleEvery = uint64(n)
+		}
+	}
+}
+
+// return the output's limiter, creating it if needed
+func (o *output) limiter() *limiter {
+	if o.limit == nil {
+		o.limit = &limiter{}
+	}
+	return o.limit
+}
+
+// reports whether an entry logged at now should be recorded. if a new
+// window has started and entries were dropped in the meantime, it also
+// returns how many and over how long so they can be summarized.
+// entries that aren't recorded are counted in drops.
+func (r *limiter) allow(now time.Time, drops *dropCounts) (ok bool, dropped uint64, over time.Duration) {
+	sampled := r.sampleEvery <= 1 || (r.seen.Add(1)-1)%r.sampleEvery == 0
+	started, allowed := r.take(now, sampled)
+	if started {
+		dropped, over = r.summary(now)
+	}
+
+	switch {
+	case !sampled:
+		drops.sampling.Add(1)
+	case !allowed:
+		drops.rateLimit.Add(1)
+	default:
+		return true, dropped, over
+	}
+	r.dropped.Add(1)
+	return false, dropped, over
+}
+
+// move to now's window if it has started, and if count is true, count an
+// entry against the limit. the window and the count are kept in a single
+// atomic so a new window can't lose entries counted in the old one.
+// reports whether a new window was started, and whether the entry was
+// within the limit.
+func (r *limiter) take(now time.Time, count bool) (started bool, allowed bool) {
+	sec := uint64(uint32(now.Unix()))
+	for {
+		w := r.window.Load()
+		next := w
+		if w>>32 != sec {
+			next = sec << 32
+		}
+		allowed = r.maxPerSecond <= 0 || int64(uint32(next)) < r.maxPerSecond
+		if count && allowed && r.maxPerSecond > 0 {
+			next++
+		}
+		if next == w || r.window.CompareAndSwap(w, next) {
+			return next>>32 != w>>32, allowed
+		}
+	}
+}
+
+// return how many entries were dropped since the last summary and over
+// how long, starting a new summary period.
+func (r *limiter) summary(now time.Time) (dropped uint64, over time.Duration) {
+	if dropped = r.dropped.Swap(0); dropped > 0 {
+		over = time.Duration(now.UnixNano() - r.since.Swap(now.UnixNano())).Round(time.Second)
+	} else {
+		r.since.Store(now.UnixNano())
+	}
+	return dropped, over
+}
+
+// apply the rate limit and sampling, writing a summary of dropped entries
+// when due. must not be called while holding l.out.mu.
+func (l *Logger) allow() bool {
+	now := l.out.now()
+	ok, dropped, over := l.out.limit.allow(now, &l.out.drops)
+	l.writeSummary(now, dropped, over)
+	return ok
+}
+
+// write a WARN entry summarizing dropped entries, if any were dropped
+func (l *Logger) writeSummary(now time.Time, dropped uint64, over time.Duration) {
+	if dropped > 0 {
+		l.write(now, WARN, fmt.Sprintf("suppressed %d messages in last %s", dropped, over), nil, "")
+	}
+}
+
+// start writing summaries of dropped entries with l's component and ID
+// once their window has passed, so they're written even if nothing else
+// is logged.
+func (r *limiter) startSummaries(l *Logger) {
+	r.stop = make(chan struct{})
+	r.done.Add(1)
+	go func() {
+		defer r.done.Done()
+		ticker := time.NewTicker(summaryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				if l.ref.closed.Load() {
+					// leave it to the next entry logged by another logger
+					continue
+				}
+				now := l.out.now()
+				if started, _ := r.take(now, false); started {
+					dropped, over := r.summary(now)
+					l.writeSummary(now, dropped, over)
+				}
+			}
+		}
+	}()
+}
+
+// stop writing summaries, if started, and wait for it to exit.
+func (r *limiter) stopSummaries() {
+	if r.stop == nil {
+		return
+	}
+	r.stopOnce.Do(func() { close(r.stop) })
+	r.done.Wait()
+}
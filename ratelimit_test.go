@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// the summary of dropped entries is written once the window has passed,
+// even if nothing else is logged
+func TestRateLimitSummaryWithoutNextEntry(t *testing.T) {
+	defer func(d time.Duration) { summaryInterval = d }(summaryInterval)
+	summaryInterval = 10 * time.Millisecond
+
+	var now atomic.Int64
+	now.Store(time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC).UnixNano())
+	clock := WithClock(func() time.Time { return time.Unix(0, now.Load()).UTC() })
+	sink := &memSink{}
+	l, _ := NewBufferLogger("limit", "1", WithSilentConsole(), clock, WithRateLimit(2), WithSinks(sink))
+	defer l.Close()
+	for range 5 {
+		l.Infoln("entry")
+	}
+	now.Add(int64(time.Second))
+
+	const want = "suppressed 3 messages in last 1s"
+	deadline := time.Now().Add(5 * time.Second)
+	for !slices.Contains(sink.messages(), want) {
+		if time.Now().After(deadline) {
+			t.Fatalf("messages = %q, want a summary %q", sink.messages(), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// entries allowed by concurrent callers never exceed the limit
+func TestRateLimitConcurrent(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	r := &limiter{maxPerSecond: 100}
+	var drops dropCounts
+	var allowed atomic.Int64
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				if ok, _, _ := r.allow(now, &drops); ok {
+					allowed.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if n := allowed.Load(); n != 100 {
+		t.Errorf("allowed %d entries, want 100", n)
+	}
+	if n := drops.rateLimit.Load(); n != 8000-100 {
+		t.Errorf("dropped %d entries, want %d", n, 8000-100)
+	}
+
+	// a new window starts the count again and reports the drops
+	ok, dropped, _ := r.allow(now.Add(time.Second), &drops)
+	if !ok || dropped != 8000-100 {
+		t.Errorf("first entry in the next window = %t with %d dropped, want true with %d", ok, dropped, 8000-100)
+	}
+}
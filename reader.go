@@ -0,0 +1,301 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry is a single log entry.
+type Entry struct {
+	Time      time.Time
+	Component string
+	Level     string
+	Message   string
+	ID        string
+	// TaskID identifies the unit of work, such as a request or job, that
+	// logged the entry, if set with WithTaskID or ContextWithTaskID. It's
+	// read from the TaskID column (see ColumnTaskID) if the file has one.
+	TaskID string
+	// Fields and Source are written with entries passed to Write and are
+	// set on entries passed to hooks and sinks. When reading log files,
+	// they're read from the Extra and Source columns if the file has them
+	// (see WithExtraColumn and WithSource).
+	//
+	// Fields are written as a JSON object with sorted keys. Times are
+	// written in RFC 3339 format, durations and errors as strings, and
+	// values that can't be represented in JSON as formatted by fmt. When
+	// read back, whole numbers are int64 and other numbers float64, and
+	// strings in the form times and durations are written in, such as
+	// "2024-03-10T12:00:00Z" and "1.5s", are time.Time and time.Duration.
+	Fields map[string]any
+	Source string
+	// Seq is the entry's sequence number if WithSequence is enabled, or 0.
+	Seq uint64
+}
+
+// ReadOption configures how log files are read.
+type ReadOption func(*readConfig)
+
+type readConfig struct {
+	timeFormat string // layout of entry timestamps
+	comma      rune   // field delimiter
+}
+
+// ReadTimeFormat sets the layout used to parse entry timestamps. It should
+// match the layout the file was written with (see WithTimeFormat).
+// Defaults to time.RFC3339.
+func ReadTimeFormat(layout string) ReadOption {
+	return func(c *readConfig) {
+		if layout != "" {
+			c.timeFormat = layout
+		}
+	}
+}
+
+// ReadDelimiter sets the field delimiter used to read csv log files. It
+// should match the delimiter the file was written with (see WithDelimiter).
+// Defaults to ','.
+func ReadDelimiter(r rune) ReadOption {
+	return func(c *readConfig) {
+		if validDelimiter(r) {
+			c.comma = r
+		}
+	}
+}
+
+func newReadConfig(opts []ReadOption) readConfig {
+	c := readConfig{timeFormat: defaultTimeFormat, comma: ','}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// ReadEntries reads all entries from a csv or json lines log file.
+func ReadEntries(path string, opts ...ReadOption) ([]Entry, error) {
+	var entries []Entry
+	err := ReadEntriesFunc(path, func(e Entry) bool {
+		entries = append(entries, e)
+		return true
+	}, opts...)
+	return entries, err
+}
+
+// ReadEntriesFunc reads entries from a log file one at a time, calling fn
+// for each of them until it returns false.
+//
+// Files with the .jsonl extension, as written with FormatJSON, are read as
+// one JSON object per line. Keys that aren't part of an entry are ignored
+// and missing ones are left empty, and blank lines are skipped.
+//
+// Other files are read as csv. Columns are located by their
+// names in the file's header, so files written with a custom column layout
+// (see WithColumns) can be read as long as they include at least one of the
+// Time, Component, Level, Message, or ID columns. Columns missing from the
+// file are left empty in the entries. The Source and Extra columns are
+// read into the entries' Source and Fields, and other columns, such as
+// unnamed fields columns, are ignored. Fields that were neutralized when written (see
+// WithSanitizeCSV) are returned in their original form.
+func ReadEntriesFunc(path string, fn func(Entry) bool, opts ...ReadOption) error {
+	cfg := newReadConfig(opts)
+	f, err := openLogReader(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if isJSONLog(path) {
+		return readJSONEntries(f, cfg, fn)
+	}
+
+	br := bufio.NewReader(f)
+	if _, err := readSchemaLine(br); err != nil {
+		return fmt.Errorf("failed to read log file header: %w", err)
+	}
+	r := csv.NewReader(br)
+	r.Comma = cfg.comma
+	r.FieldsPerRecord = -1 // rows may have extra columns
+	r.ReuseRecord = true
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read log file header: %w", err)
+	}
+	cols, err := newColumnIndex(header, cfg)
+	if err != nil {
+		return err
+	}
+
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read log file: %w", err)
+		}
+		e, err := cols.parse(record)
+		if err != nil {
+			line, _ := r.FieldPos(0)
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if !fn(e) {
+			return nil
+		}
+	}
+}
+
+// reports whether a log file holds json lines, going by its extension
+func isJSONLog(path string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(path, ".gz"), FormatJSON.ext())
+}
+
+// read entries from a json lines log file, calling fn for each of them
+// until it returns false
+func readJSONEntries(r io.Reader, cfg readConfig, fn func(Entry) bool) error {
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		// lines are read whole, however long, since messages aren't limited
+		b, err := br.ReadBytes('\n')
+		if len(bytes.TrimSpace(b)) > 0 {
+			e, perr := parseJSONEntry(b, cfg.timeFormat)
+			if perr != nil {
+				return fmt.Errorf("line %d: %w", line, perr)
+			}
+			if !fn(e) {
+				return nil
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read log file: %w", err)
+		}
+	}
+}
+
+// parse a line of a json lines log file into an entry
+func parseJSONEntry(line []byte, timeFormat string) (Entry, error) {
+	var je jsonEntry
+	d := json.NewDecoder(bytes.NewReader(line))
+	d.UseNumber()
+	if err := d.Decode(&je); err != nil {
+		return Entry{}, fmt.Errorf("invalid entry: %w", err)
+	}
+	if je.Fields != nil {
+		je.Fields = typedValues(je.Fields).(map[string]any)
+	}
+	e := Entry{
+		Component: je.Component,
+		Level:     je.Level,
+		Message:   je.Message,
+		ID:        je.ID,
+		TaskID:    je.TaskID,
+		Fields:    je.Fields,
+		Source:    je.Source,
+		Seq:       je.Seq,
+	}
+	if je.Time != "" {
+		t, err := parseTime(je.Time, timeFormat)
+		if err != nil {
+			return Entry{}, fmt.Errorf("invalid timestamp: %w", err)
+		}
+		e.Time = t
+	}
+	return e, nil
+}
+
+// positions of the standard columns in a csv log file. -1 if missing.
+type columnIndex struct {
+	time, component, level, message, id int
+	taskID, seq, source, extra          int
+	timeFormat                          string
+}
+
+// locate the standard columns in a header row
+func newColumnIndex(header []string, cfg readConfig) (columnIndex, error) {
+	idx := columnIndex{-1, -1, -1, -1, -1, -1, -1, -1, -1, cfg.timeFormat}
+	found := false
+	for i, name := range header {
+		var col *int
+		switch name {
+		case ColumnTime.Name:
+			col = &idx.time
+		case ColumnComponent.Name:
+			col = &idx.component
+		case ColumnLevel.Name:
+			col = &idx.level
+		case ColumnMessage.Name:
+			col = &idx.message
+		case ColumnID.Name:
+			col = &idx.id
+		case ColumnTaskID.Name:
+			col = &idx.taskID
+		case seqColumn:
+			col = &idx.seq
+		case sourceColumn:
+			col = &idx.source
+		case extraColumn:
+			col = &idx.extra
+		}
+		if col != nil && *col < 0 {
+			*col = i
+			found = true
+		}
+	}
+	if !found {
+		return idx, fmt.Errorf("unexpected log file header: %v", header)
+	}
+	return idx, nil
+}
+
+// parse a csv record into an entry
+func (c columnIndex) parse(record []string) (Entry, error) {
+	field := func(i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+	// the columns that may have been sanitized when written
+	text := func(i int) string {
+		return unsanitizeField(field(i))
+	}
+	var e Entry
+	if ts := field(c.time); ts != "" {
+		t, err := parseTime(ts, c.timeFormat)
+		if err != nil {
+			return Entry{}, fmt.Errorf("invalid timestamp: %w", err)
+		}
+		e.Time = t
+	}
+	e.Component = text(c.component)
+	e.Level = text(c.level)
+	e.Message = text(c.message)
+	e.ID = text(c.id)
+	e.TaskID = text(c.taskID)
+	e.Source = field(c.source)
+	if seq := field(c.seq); seq != "" {
+		n, err := strconv.ParseUint(seq, 10, 64)
+		if err != nil {
+			return Entry{}, fmt.Errorf("invalid %s column: %w", seqColumn, err)
+		}
+		e.Seq = n
+	}
+	if extra := field(c.extra); extra != "" {
+		fields, err := decodeFields([]byte(extra))
+		if err != nil {
+			return Entry{}, fmt.Errorf("invalid %s column: %w", extraColumn, err)
+		}
+		e.Fields = fields
+	}
+	return e, nil
+}
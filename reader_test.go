@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	tempLogDir(t)
+	now := time.Date(2024, 3, 10, 12, 30, 15, 0, time.UTC)
+	l := NewLogger("json", "1", WithSilentConsole(), WithFormat(FormatJSON), WithSequence(true),
+		WithSource(true), WithClock(func() time.Time { return now }))
+	defer l.Close()
+	want := []Entry{
+		{Time: now, Component: "json", Level: INFO, Message: "plain", ID: "1"},
+		{
+			Time: now.Add(time.Second), Component: "api", Level: ERROR, Message: "with, \"quotes\"\nand lines",
+			ID: "7", TaskID: "task-3", Source: "handler.go:42",
+			Fields: map[string]any{
+				"status":  int64(503),
+				"ratio":   0.25,
+				"ok":      false,
+				"elapsed": 1500 * time.Millisecond,
+				"tags":    []any{"a", "b"},
+				"request": map[string]any{"path": "/v1", "bytes": int64(12)},
+			},
+		},
+	}
+	for _, e := range want {
+		if err := l.Write(e); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	if filepath.Ext(l.FilePath()) != ".jsonl" {
+		t.Errorf("FilePath = %s, want a .jsonl file", l.FilePath())
+	}
+	got := readLog(t, l)
+	if len(got) != len(want) {
+		t.Fatalf("read %d entries, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		w.Seq = uint64(i + 1)
+		if !g.Time.Equal(w.Time) || g.Component != w.Component || g.Level != w.Level || g.Message != w.Message ||
+			g.ID != w.ID || g.TaskID != w.TaskID || g.Source != w.Source || g.Seq != w.Seq {
+			t.Errorf("entry %d = %+v, want %+v", i, g, w)
+		}
+		if !equalFields(g.Fields, w.Fields) {
+			t.Errorf("entry %d fields = %#v, want %#v", i, g.Fields, w.Fields)
+		}
+	}
+}
+
+func TestJSONRoundTripTimeFormat(t *testing.T) {
+	tempLogDir(t)
+	const layout = "2006-01-02 15:04:05.000"
+	now := time.Date(2024, 3, 10, 12, 30, 15, 250e6, time.UTC)
+	l := NewLogger("json", "1", WithSilentConsole(), WithFormat(FormatJSON), WithTimeFormat(layout),
+		WithClock(func() time.Time { return now }))
+	defer l.Close()
+	l.Infoln("entry")
+	l.Flush()
+
+	if _, err := ReadEntries(l.FilePath()); err == nil {
+		t.Error("ReadEntries with the default layout succeeded, want an invalid timestamp error")
+	}
+	entries, err := ReadEntries(l.FilePath(), ReadTimeFormat(layout))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || !entries[0].Time.Equal(now) {
+		t.Errorf("entries = %+v, want one at %s", entries, now)
+	}
+}
+
+// lines from other writers may have keys of their own or be missing some
+func TestReadJSONLenient(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "other.jsonl")
+	data := `{"time":"2024-03-10T12:00:00Z","level":"INFO","message":"full","component":"api","id":"1","host":"web-1"}
+
+{"message":"minimal"}
+{"level":"WARN","message":"no time","extra":{"nested":true}}
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ReadEntries(path)
+	if err != nil {
+		t.Fatalf("ReadEntries: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("read %d entries, want 3", len(entries))
+	}
+	if e := entries[0]; e.Component != "api" || e.ID != "1" || e.Time.IsZero() {
+		t.Errorf("full entry = %+v", e)
+	}
+	if e := entries[1]; e.Message != "minimal" || e.Level != "" || !e.Time.IsZero() {
+		t.Errorf("minimal entry = %+v", e)
+	}
+	if e := entries[2]; e.Level != WARN || e.Message != "no time" {
+		t.Errorf("entry without a time = %+v", e)
+	}
+}
+
+func TestReadJSONInvalidLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.jsonl")
+	data := "{\"message\":\"ok\"}\nnot json\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadEntries(path); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("ReadEntries = %v, want an error for line 2", err)
+	}
+}
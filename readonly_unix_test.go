@@ -0,0 +1,74 @@
+//go:build unix
+
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// a log directory the test process can't write to
+func readOnlyDir(t *testing.T) string {
+	t.Helper()
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to read only directories")
+	}
+	dir := filepath.Join(t.TempDir(), "logs")
+	if err := os.Mkdir(dir, 0555); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(dir, 0755) })
+	return dir
+}
+
+func TestReadOnlyLogDir(t *testing.T) {
+	t.Setenv("LOG_DIR", readOnlyDir(t))
+	l, err := NewLoggerE("readonly", "1", WithSilentConsole())
+	if err == nil {
+		l.Close()
+		t.Fatal("NewLoggerE with a read only LOG_DIR succeeded, want an error")
+	}
+	if !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("NewLoggerE error = %v, want a permission denied error", err)
+	}
+}
+
+func TestReadOnlyLogDirFallback(t *testing.T) {
+	t.Setenv("LOG_DIR", readOnlyDir(t))
+	var console strings.Builder
+	l, err := NewLoggerE("readonly", "1", WithOutput(&console), WithFallbackConsole(true))
+	if err != nil {
+		t.Fatalf("NewLoggerE with fallback: %v", err)
+	}
+	l.Info("still displayed")
+	l.Close()
+	if !strings.Contains(console.String(), "still displayed") {
+		t.Errorf("console = %q, want the message displayed", console.String())
+	}
+}
+
+// a LOG_DIR that's a file can't be written to even by root
+func TestFallbackConsoleWhenLogDirIsAFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LOG_DIR", file)
+	var console strings.Builder
+	l, err := NewLoggerE("fallback", "1", WithOutput(&console), WithFallbackConsole(true))
+	if err != nil {
+		t.Fatalf("NewLoggerE with fallback: %v", err)
+	}
+	l.Info("still displayed")
+	if err := l.Close(); err != nil {
+		t.Errorf("Close = %v", err)
+	}
+	if !strings.Contains(console.String(), "still displayed") {
+		t.Errorf("console = %q, want the message displayed", console.String())
+	}
+	if entries, err := os.ReadFile(file); err != nil || len(entries) != 0 {
+		t.Errorf("LOG_DIR file = %q, %v, want it left empty", entries, err)
+	}
+}
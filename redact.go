@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// replacement for the values of redacted fields
+const redacted = "[REDACTED]"
+
+// redaction rules of a logger, shared with derived loggers. they're kept
+// per logger rather than on the output so a logger writing to a file that
+// another logger already opened still redacts what it writes.
+type redactor struct {
+	rules   []redactRule    // patterns redacted from messages and fields
+	keys    map[string]bool // lowercased keys of fields whose values are redacted
+	console bool            // whether displayed messages are redacted too
+}
+
+// a pattern to redact and what to replace it with
+type redactRule struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// WithRedactPattern replaces matches of re in messages and string field
+// values with replacement before they're written. The replacement can
+// refer to submatches the same way as regexp.Regexp.ReplaceAllString.
+// Patterns are applied in the order they're added.
+func WithRedactPattern(re *regexp.Regexp, replacement string) Option {
+	return func(l *Logger) {
+		l.redactor.rules = append(l.redactor.rules, redactRule{re: re, replacement: replacement})
+	}
+}
+
+// WithRedactKeys replaces the values of fields with the given keys with
+// "[REDACTED]" before they're written. Keys are case insensitive.
+func WithRedactKeys(keys ...string) Option {
+	return func(l *Logger) {
+		if l.redactor.keys == nil {
+			l.redactor.keys = make(map[string]bool, len(keys))
+		}
+		for _, key := range keys {
+			l.redactor.keys[strings.ToLower(key)] = true
+		}
+	}
+}
+
+// WithRedactConsole applies the redaction rules to displayed messages
+// as well as the log file. By default only the log file is redacted.
+func WithRedactConsole(redact bool) Option {
+	return func(l *Logger) {
+		l.redactor.console = redact
+	}
+}
+
+// whether any redaction rules are configured
+func (r *redactor) redacting() bool {
+	return len(r.rules) > 0 || len(r.keys) > 0
+}
+
+// apply the redaction patterns to s
+func (r *redactor) redact(s string) string {
+	for _, rule := range r.rules {
+		s = rule.re.ReplaceAllString(s, rule.replacement)
+	}
+	return s
+}
+
+// return a copy of fields with sensitive values redacted, including those
+// nested in maps and slices
+func (r *redactor) redactFields(fields map[string]any) map[string]any {
+	if len(fields) == 0 {
+		return fields
+	}
+	out := make(map[string]any, len(fields))
+	for k, v := range fields {
+		if r.keys[strings.ToLower(k)] {
+			v = redacted
+		} else {
+			v = r.redactValue(v)
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// return a copy of a field value with its strings redacted, and the values
+// of nested maps with sensitive keys replaced
+func (r *redactor) redactValue(v any) any {
+	switch v := v.(type) {
+	case string:
+		return r.redact(v)
+	case map[string]any:
+		return r.redactFields(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = r.redactValue(item)
+		}
+		return out
+	case []string:
+		out := make([]string, len(v))
+		for i, item := range v {
+			out[i] = r.redact(item)
+		}
+		return out
+	}
+	return v
+}
+
+// redact a displayed attribute, including the message
+func (r *redactor) redactAttr(a slog.Attr) slog.Attr {
+	if r.keys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, redacted)
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, r.redact(a.Value.String()))
+	case slog.KindAny:
+		return slog.Any(a.Key, r.redactValue(a.Value.Any()))
+	}
+	return a
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var cardPattern = regexp.MustCompile(`\d{4}-\d{4}-\d{4}-\d{4}`)
+
+// log an entry with fields through a logger with the given redaction
+// options and return the fields its sink received
+func redactedFields(t *testing.T, fields map[string]any, opts ...Option) map[string]any {
+	t.Helper()
+	sink := &memSink{}
+	l, _ := NewBufferLogger("redact", "1", append([]Option{WithSilentConsole(), WithSinks(sink)}, opts...)...)
+	l.InfoWith(fields, "entry")
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if len(sink.entries) != 1 {
+		t.Fatalf("sink got %d entries, want 1", len(sink.entries))
+	}
+	return sink.entries[0].Fields
+}
+
+func TestRedactNestedFields(t *testing.T) {
+	got := redactedFields(t, map[string]any{
+		"request": map[string]any{
+			"user":     "alice",
+			"password": "hunter2",
+			"headers":  map[string]any{"Authorization": "Bearer abc"},
+		},
+		"attempts": []any{
+			map[string]any{"password": "hunter3"},
+			"card 4111-1111-1111-1111",
+		},
+		"cards": []string{"4111-1111-1111-1111", "none"},
+	}, WithRedactKeys("password", "authorization"), WithRedactPattern(cardPattern, "****"))
+
+	want := map[string]any{
+		"request": map[string]any{
+			"user":     "alice",
+			"password": redacted,
+			"headers":  map[string]any{"Authorization": redacted},
+		},
+		"attempts": []any{
+			map[string]any{"password": redacted},
+			"card ****",
+		},
+		"cards": []string{"****", "none"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fields = %#v\nwant %#v", got, want)
+	}
+}
+
+func TestRedactDoesNotModifyCallerFields(t *testing.T) {
+	nested := map[string]any{"password": "hunter2"}
+	list := []string{"4111-1111-1111-1111"}
+	redactedFields(t, map[string]any{"nested": nested, "list": list},
+		WithRedactKeys("password"), WithRedactPattern(cardPattern, "****"))
+	if nested["password"] != "hunter2" || list[0] != "4111-1111-1111-1111" {
+		t.Error("redaction modified the caller's fields")
+	}
+}
+
+func TestRedactConsoleNestedFields(t *testing.T) {
+	var console strings.Builder
+	l, _ := NewBufferLogger("redact", "1", WithOutput(&console),
+		WithRedactKeys("password"), WithRedactConsole(true))
+	defer l.Close()
+	l.InfoWith(map[string]any{"request": map[string]any{"password": "hunter2"}}, "login")
+	if strings.Contains(console.String(), "hunter2") {
+		t.Errorf("displayed message wasn't redacted: %q", console.String())
+	}
+}
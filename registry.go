@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+// outputs shared by every logger in the process writing the same log
+// files, so their writes are serialized through a single writer instead
+// of interleaving through separate file handles.
+var (
+	outputsMu sync.Mutex
+	outputs   = make(map[string]*output)
+)
+
+// key identifying the log files written to a directory. loggers with the
+// same directory, file component, and file extension always write to the
+// same daily file.
+// symlinks are resolved so a directory reached through different paths
+// still gets a single output, and its files are only initialized once.
+func outputKey(dir, component, ext string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	return pathKey(filepath.Join(dir, logFilePrefix(component)+"*"+ext))
+}
+
+// a logger's reference to its output, shared with derived loggers
+type outputRef struct {
+	once   sync.Once   // releases the reference
+	closed atomic.Bool // whether the logger has been closed, even if the output is still open
+	sinks  sinkSet     // the logger's sinks, closed along with it
+}
+
+// report whether an output other than o is writing to the file with the
+// given path key, either because it was switched to it or because it's
+// the output's current daily file.
+// must be called while holding outputsMu.
+func fileInUse(key string, o *output) bool {
+	for _, shared := range outputs {
+		if shared == o {
+			continue
+		}
+		shared.mu.Lock()
+		path := shared.path
+		shared.mu.Unlock()
+		if pathKey(path) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// release a logger's reference to its output, closing the output once
+// no loggers reference it. otherwise any pending entries are flushed.
+func releaseOutput(o *output) error {
+	outputsMu.Lock()
+	o.refs--
+	last := o.refs <= 0
+	if last && outputs[o.key] == o {
+		delete(outputs, o.key)
+	}
+	outputsMu.Unlock()
+	if !last {
+		return o.sync()
+	}
+	return o.close()
+}
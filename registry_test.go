@@ -0,0 +1,176 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSharedOutputKeepsRedactionAndSinks(t *testing.T) {
+	tempLogDir(t)
+	a := NewLogger("a", "1", WithSilentConsole(), WithExtraColumn(true))
+	defer a.Close()
+	sink := &memSink{}
+	b := NewLogger("b", "2", WithSilentConsole(), WithRedactKeys("password"), WithSinks(sink))
+
+	if a.out != b.out {
+		t.Fatal("loggers writing the same file don't share an output")
+	}
+	a.InfoWith(map[string]any{"user": "alice"}, "from a")
+	b.InfoWith(map[string]any{"password": "hunter2"}, "from b")
+
+	for _, e := range readLog(t, a) {
+		if e.Component == "b" && e.Fields["password"] != redacted {
+			t.Errorf("password = %v, want %q", e.Fields["password"], redacted)
+		}
+		if e.Component == "a" && e.Fields["user"] != "alice" {
+			t.Errorf("user = %v, want alice", e.Fields["user"])
+		}
+	}
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := sink.messages(); !slices.Equal(got, []string{"from b"}) {
+		t.Errorf("sink got %q, want only the entry from b", got)
+	}
+	if !sink.closed {
+		t.Error("sink wasn't closed with its logger")
+	}
+}
+
+func TestSharedOutputKeepsConsoleRedaction(t *testing.T) {
+	tempLogDir(t)
+	a := NewLogger("a", "1", WithSilentConsole())
+	defer a.Close()
+	var console strings.Builder
+	b := NewLogger("b", "2", WithOutput(&console), WithRedactKeys("token"), WithRedactConsole(true))
+	defer b.Close()
+
+	b.InfoWith(map[string]any{"token": "abc123"}, "request")
+	if strings.Contains(console.String(), "abc123") {
+		t.Errorf("displayed message wasn't redacted: %q", console.String())
+	}
+}
+
+func TestClosedLoggerStopsWritingSharedFile(t *testing.T) {
+	tempLogDir(t)
+	a := NewLogger("a", "1", WithSilentConsole())
+	b := NewLogger("b", "2", WithSilentConsole())
+	defer b.Close()
+
+	if err := a.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	a.Info("after close")
+	if err := a.Write(Entry{Level: INFO, Message: "write after close"}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Write after Close = %v, want ErrClosed", err)
+	}
+	if err := a.Rotate(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Rotate after Close = %v, want ErrClosed", err)
+	}
+	b.Info("from b")
+
+	entries := readLog(t, b)
+	if len(entries) != 1 || entries[0].Message != "from b" {
+		t.Errorf("entries = %+v, want only the entry from b", entries)
+	}
+}
+
+func TestSharedOutputStress(t *testing.T) {
+	tempLogDir(t)
+	const loggers, perLogger = 8, 500
+	ls := make([]*Logger, loggers)
+	for i := range ls {
+		ls[i] = NewLogger(fmt.Sprintf("worker-%d", i), strconv.Itoa(i), WithSilentConsole())
+	}
+
+	var wg sync.WaitGroup
+	for i, l := range ls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range perLogger {
+				l.InfoWith(map[string]any{"n": n}, "entry %d from %d, with \"quotes\", commas\nand newlines", n, i)
+			}
+		}()
+	}
+	wg.Wait()
+
+	path := ls[0].FilePath()
+	for _, l := range ls {
+		if err := l.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+	}
+	entries, err := ReadEntries(path)
+	if err != nil {
+		t.Fatalf("ReadEntries: %v", err)
+	}
+	if len(entries) != loggers*perLogger {
+		t.Fatalf("got %d entries, want %d", len(entries), loggers*perLogger)
+	}
+	next := make(map[string]int)
+	for _, e := range entries {
+		i, _ := strconv.Atoi(e.ID)
+		want := fmt.Sprintf("entry %d from %d, with \"quotes\", commas\nand newlines", next[e.ID], i)
+		if e.Component != "worker-"+e.ID || e.Message != want {
+			t.Fatalf("corrupted entry %+v, want message %q", e, want)
+		}
+		next[e.ID]++
+	}
+}
+
+func TestConcurrentConstructionWritesOneHeader(t *testing.T) {
+	tempLogDir(t)
+	const n = 32
+	ls := make([]*Logger, n)
+	errs := make([]error, n)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			ls[i], errs[i] = NewLoggerE("init", strconv.Itoa(i), WithSilentConsole())
+		}()
+	}
+	close(start)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("NewLoggerE: %v", err)
+		}
+	}
+	for _, l := range ls {
+		l.Info("hello")
+	}
+	path := ls[0].FilePath()
+	for _, l := range ls {
+		l.Close()
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := "Time,Component,Level,Message,ID"
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	headers := 0
+	for _, line := range lines {
+		if line == header {
+			headers++
+		}
+	}
+	if headers != 1 || lines[0] != header {
+		t.Errorf("found %d header lines, want exactly one at the start:\n%s", headers, data)
+	}
+	if len(lines) != n+1 {
+		t.Errorf("got %d lines, want %d", len(lines), n+1)
+	}
+}
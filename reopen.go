@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// ErrClosed is returned when operating on a logger whose log file
+// has been closed.
+var ErrClosed = errors.New("log file is closed")
+
+// ErrNoFile is returned when trying to reopen or rotate the log file of a
+// logger that writes to a writer, such as one created with NewWriterLogger.
+var ErrNoFile = errors.New("logger doesn't write to a log file")
+
+// Reopen flushes and closes the log file, then opens it again at the same
+// path, creating it with a header if it no longer exists. This lets external
+// tools like logrotate move the log file aside, after which new entries are
+// written to a fresh file. If the file can't be reopened, the logger keeps
+// writing to the old one.
+func (l *Logger) Reopen() error {
+	o := l.out
+	if o.async != nil {
+		o.async.wait()
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed || l.ref.closed.Load() {
+		return ErrClosed
+	} else if o.file == nil {
+		return ErrNoFile
+	}
+	file, err := o.openFile(o.path)
+	if err != nil {
+		return err
+	}
+	err = o.flush()
+	if cerr := o.closeFile(); err == nil {
+		err = cerr
+	}
+	o.setFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to close log file %q: %w", o.path, err)
+	}
+	return nil
+}
+
+// SetOutputFile switches the logger to writing to the log file at path,
+// creating it and any missing directories, with a header for csv files,
+// if needed. Queued and buffered entries are written to the current file
+// first, which is then closed and left as it is. The switch applies to
+// every logger sharing the output, such as derived loggers. Since the
+// file name is no longer dated, the logger stops rolling over to a new
+// file each day, though WithMaxSize still rotates it. Loggers created
+// afterwards for the original log directory get their own output.
+//
+// If the new file can't be opened, such as when it has a different
+// header or another logger is already writing to it, the logger keeps
+// writing to the current file and the error is returned.
+func (l *Logger) SetOutputFile(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve log file path: %w", err)
+	}
+	o := l.out
+	if o.async != nil {
+		o.async.wait()
+	}
+	outputsMu.Lock()
+	defer outputsMu.Unlock()
+	key := pathKey(path)
+	if fileInUse(key, o) {
+		return fmt.Errorf("log file %q is already being written to by another logger", path)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed || l.ref.closed.Load() {
+		return ErrClosed
+	} else if o.file == nil {
+		return ErrNoFile
+	}
+	dir := filepath.Dir(path)
+	if err := createLogDir(dir, dirPerm(o.dirMode)); err != nil {
+		return fmt.Errorf("failed to create log directory %q: %w", dir, err)
+	}
+	file, err := o.openFile(path)
+	if err != nil {
+		return err
+	}
+
+	o.flushRepeats()
+	err = o.flush()
+	if cerr := o.closeFile(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to close log file %q: %w", o.path, err)
+	}
+	o.setFile(file)
+	o.dir, o.path, o.fixedPath = dir, path, true
+	if outputs[o.key] == o {
+		delete(outputs, o.key)
+	}
+	o.key = key
+	outputs[key] = o
+	return err
+}
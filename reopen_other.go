@@ -0,0 +1,8 @@
+//go:build !unix
+
+package logger
+
+// there's no SIGHUP on this platform, so Reopen is never called
+func (l *Logger) ReopenOnSignal() (stop func()) {
+	return func() {}
+}
@@ -0,0 +1,230 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReopenAfterRename(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("reopen", "1", WithSilentConsole())
+	defer l.Close()
+	path := l.FilePath()
+
+	l.Info("before rotation")
+	l.Flush()
+	rotated := path + ".1"
+	if err := os.Rename(path, rotated); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("still in the old file")
+	if err := l.Reopen(); err != nil {
+		t.Fatalf("Reopen: %v", err)
+	}
+	l.Info("after rotation")
+
+	old, err := ReadEntries(rotated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(old) != 2 || old[1].Message != "still in the old file" {
+		t.Errorf("rotated file has %+v", old)
+	}
+	fresh := readLog(t, l)
+	if len(fresh) != 1 || fresh[0].Message != "after rotation" {
+		t.Errorf("fresh file has %+v", fresh)
+	}
+}
+
+func TestReopenConcurrentWithLogging(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("reopen", "1", WithSilentConsole())
+	defer l.Close()
+	path := l.FilePath()
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 200 {
+				l.Info("entry")
+			}
+		}()
+	}
+	var backups []string
+	for i := range 5 {
+		backup := path + "." + string(rune('a'+i))
+		if err := os.Rename(path, backup); err != nil {
+			t.Fatal(err)
+		}
+		if err := l.Reopen(); err != nil {
+			t.Fatalf("Reopen: %v", err)
+		}
+		backups = append(backups, backup)
+	}
+	wg.Wait()
+
+	total := len(readLog(t, l))
+	for _, b := range backups {
+		entries, err := ReadEntries(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total += len(entries)
+	}
+	if total != 800 {
+		t.Errorf("found %d entries across the files, want 800", total)
+	}
+}
+
+func TestReopenClosed(t *testing.T) {
+	l, _ := NewBufferLogger("reopen", "1", WithSilentConsole())
+	if err := l.Reopen(); err != ErrNoFile {
+		t.Errorf("Reopen of a writer logger = %v, want ErrNoFile", err)
+	}
+	tempLogDir(t)
+	l = NewLogger("reopen", "1", WithSilentConsole())
+	l.Close()
+	if err := l.Reopen(); err != ErrClosed {
+		t.Errorf("Reopen after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestSetOutputFile(t *testing.T) {
+	dir := tempLogDir(t)
+	l := NewLogger("switch", "1", WithSilentConsole())
+	defer l.Close()
+	a := l.FilePath()
+	b := filepath.Join(dir, "configured", "app.csv")
+
+	l.Info("to a")
+	if err := l.SetOutputFile(b); err != nil {
+		t.Fatalf("SetOutputFile: %v", err)
+	}
+	l.Child("child").Info("to b")
+	if got := l.FilePath(); got != b {
+		t.Errorf("FilePath = %s, want %s", got, b)
+	}
+
+	for path, want := range map[string]string{a: "to a", b: "to b"} {
+		entries, err := ReadEntries(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 || entries[0].Message != want {
+			t.Errorf("%s has %+v, want only %q", filepath.Base(path), entries, want)
+		}
+	}
+}
+
+// a switched file isn't dated, so the logger stays on it past midnight
+func TestSetOutputFileStopsRollover(t *testing.T) {
+	dir := tempLogDir(t)
+	now := time.Date(2024, 3, 10, 23, 59, 0, 0, time.UTC)
+	l := NewLogger("switch", "1", WithSilentConsole(), WithClock(func() time.Time { return now }))
+	defer l.Close()
+	path := filepath.Join(dir, "fixed.csv")
+	if err := l.SetOutputFile(path); err != nil {
+		t.Fatalf("SetOutputFile: %v", err)
+	}
+	l.Info("before midnight")
+	now = now.Add(2 * time.Minute)
+	l.Info("after midnight")
+	if entries := readLog(t, l); l.FilePath() != path || len(entries) != 2 {
+		t.Errorf("%s has %d entries, want both in %s", l.FilePath(), len(entries), path)
+	}
+}
+
+func TestSetOutputFileHeaderMismatch(t *testing.T) {
+	dir := tempLogDir(t)
+	l := NewLogger("switch", "1", WithSilentConsole())
+	defer l.Close()
+	a := l.FilePath()
+	b := filepath.Join(dir, "other.csv")
+	if err := os.WriteFile(b, []byte("Time,Level,Message\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.SetOutputFile(b); !errors.Is(err, ErrHeaderMismatch) {
+		t.Errorf("SetOutputFile = %v, want ErrHeaderMismatch", err)
+	}
+	l.Info("still to a")
+	if entries := readLog(t, l); l.FilePath() != a || len(entries) != 1 {
+		t.Errorf("%s has %d entries, want the entry in %s", l.FilePath(), len(entries), a)
+	}
+}
+
+func TestSetOutputFileInUse(t *testing.T) {
+	dir := tempLogDir(t)
+	a := NewLogger("first", "1", WithSilentConsole(), WithPerComponentFile(true))
+	defer a.Close()
+	b := NewLogger("second", "1", WithSilentConsole(), WithPerComponentFile(true))
+	defer b.Close()
+	if err := b.SetOutputFile(a.FilePath()); err == nil {
+		t.Error("SetOutputFile to another logger's file succeeded, want an error")
+	}
+
+	// the original directory's file is free again for new loggers
+	path := filepath.Join(dir, "moved.csv")
+	if err := a.SetOutputFile(path); err != nil {
+		t.Fatalf("SetOutputFile: %v", err)
+	}
+	c := NewLogger("first", "2", WithSilentConsole(), WithPerComponentFile(true))
+	defer c.Close()
+	if c.FilePath() == path {
+		t.Errorf("new logger shares the switched file %s, want its own", path)
+	}
+}
+
+func TestSetOutputFileConcurrentWithLogging(t *testing.T) {
+	dir := tempLogDir(t)
+	l := NewLogger("switch", "1", WithSilentConsole())
+	defer l.Close()
+	first := l.FilePath()
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				l.Info("entry")
+			}
+		}()
+	}
+	var paths []string
+	for i := range 5 {
+		path := filepath.Join(dir, "switched", string(rune('a'+i))+".csv")
+		if err := l.SetOutputFile(path); err != nil {
+			t.Errorf("SetOutputFile: %v", err)
+		}
+		paths = append(paths, path)
+	}
+	wg.Wait()
+	l.Flush()
+
+	total := 0
+	for _, path := range append(paths, first) {
+		entries, err := ReadEntries(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total += len(entries)
+	}
+	if total != 400 {
+		t.Errorf("files hold %d entries, want all 400", total)
+	}
+}
+
+func TestSetOutputFileClosed(t *testing.T) {
+	dir := tempLogDir(t)
+	l := NewLogger("switch", "1", WithSilentConsole())
+	l.Close()
+	if err := l.SetOutputFile(filepath.Join(dir, "closed.csv")); !errors.Is(err, ErrClosed) {
+		t.Errorf("SetOutputFile after Close = %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,39 @@
+//go:build unix
+
+package logger
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ReopenOnSignal calls Reopen whenever the process receives SIGHUP, which
+// is how logrotate signals that log files have been rotated. Errors are
+// recorded and returned by Err. Call the returned function to stop.
+// Platforms without SIGHUP, such as Windows, never call Reopen, so there
+// it needs to be called directly.
+func (l *Logger) ReopenOnSignal() (stop func()) {
+	sigs := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigs, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-sigs:
+				if err := l.Reopen(); err != nil && !errors.Is(err, ErrClosed) {
+					l.out.mu.Lock()
+					l.out.err = err
+					l.out.mu.Unlock()
+				}
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sigs)
+		close(done)
+	}
+}
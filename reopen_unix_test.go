@@ -0,0 +1,43 @@
+//go:build unix
+
+package logger
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReopenOnSignal(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("reopen", "1", WithSilentConsole())
+	defer l.Close()
+	stop := l.ReopenOnSignal()
+	defer stop()
+	path := l.FilePath()
+
+	l.Info("before rotation")
+	l.Flush()
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("log file wasn't reopened after SIGHUP")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	l.Info("after rotation")
+	entries := readLog(t, l)
+	if len(entries) != 1 || entries[0].Message != "after rotation" {
+		t.Errorf("fresh file has %+v", entries)
+	}
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// matches log files created by the logger, including per component files,
+// rotated backups, and compressed files. the first submatch is the date
+// the file was created for.
+var logFilePattern = regexp.MustCompile(`^log-(?:[a-z0-9_-]+-)?(\d{2}-\d{2}-\d{4})(\.\d+)?\.(csv|tsv|jsonl|log)(\.gz)?$`)
+
+// parse the date a log file was created for from its name. returns false
+// if the name doesn't match the log file naming format.
+func parseLogFileDate(name string, loc *time.Location) (time.Time, bool) {
+	m := logFilePattern.FindStringSubmatch(name)
+	if m == nil {
+		return time.Time{}, false
+	}
+	date, err := time.ParseInLocation("02-01-2006", m[1], loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// remove log files in dir starting with prefix whose day ended before
+// cutoff. files are dated by their name rather than their modification
+// time, and files that don't match the log file naming format or belong
+// to another component are left alone. returns the number of files
+// removed.
+func removeOldLogs(dir string, prefix string, cutoff time.Time) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read log directory %q: %w", dir, err)
+	}
+	removed := 0
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		date, ok := parseLogFileDate(e.Name(), cutoff.Location())
+		// the date must follow the prefix directly, so the shared files
+		// aren't mistaken for a component's or the other way around
+		if !ok || !strings.HasPrefix(e.Name(), prefix+formatDate(date)) || date.AddDate(0, 0, 1).After(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, e.Name())); err != nil {
+			return removed, fmt.Errorf("failed to remove old log file: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// remove log files older than maxAge, if set.
+func (o *output) cleanup(now time.Time) {
+	if o.maxAge <= 0 {
+		return
+	}
+	removed, err := removeOldLogs(o.dir, logFilePrefix(o.fileComponent), now.Add(-o.maxAge))
+	if err != nil {
+		log.Print(err)
+	}
+	if removed > 0 {
+		log.Printf("removed %d log file(s) older than %s", removed, o.maxAge)
+	}
+}
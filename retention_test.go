@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// create empty files in dir with the given names
+func touch(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// names of the files in dir
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestMaxAgeRemovesOnlyOwnFiles(t *testing.T) {
+	dir := tempLogDir(t)
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	touch(t, dir,
+		"log-01-03-2024.csv",
+		"log-01-03-2024.1.csv.gz",
+		"log-api-01-03-2024.csv",
+		"log-db-01-03-2024.csv",
+		"log-09-03-2024.csv",
+		"notes-01-03-2024.txt",
+	)
+
+	l := NewLogger("api", "1", WithSilentConsole(), WithPerComponentFile(true),
+		WithClock(func() time.Time { return now }), WithMaxAge(72*time.Hour))
+	l.Close()
+	want := []string{
+		"log-01-03-2024.1.csv.gz",
+		"log-01-03-2024.csv",
+		"log-09-03-2024.csv",
+		"log-api-10-03-2024.csv",
+		"log-db-01-03-2024.csv",
+		"notes-01-03-2024.txt",
+	}
+	if got := listDir(t, dir); !slices.Equal(got, want) {
+		t.Errorf("component logger left %q\nwant %q", got, want)
+	}
+
+	l = NewLogger("shared", "1", WithSilentConsole(),
+		WithClock(func() time.Time { return now }), WithMaxAge(72*time.Hour))
+	l.Close()
+	want = []string{
+		"log-09-03-2024.csv",
+		"log-10-03-2024.csv",
+		"log-api-10-03-2024.csv",
+		"log-db-01-03-2024.csv",
+		"notes-01-03-2024.txt",
+	}
+	if got := listDir(t, dir); !slices.Equal(got, want) {
+		t.Errorf("shared logger left %q\nwant %q", got, want)
+	}
+}
+
+func TestMaxAgeOnRollover(t *testing.T) {
+	dir := tempLogDir(t)
+	now := time.Date(2024, 3, 10, 23, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	l := NewLogger("retention", "1", WithSilentConsole(), WithClock(clock), WithMaxAge(24*time.Hour))
+	defer l.Close()
+	l.Info("first day")
+
+	now = now.Add(26 * time.Hour)
+	l.Info("second day")
+	l.Flush()
+	if got, want := listDir(t, dir), []string{"log-12-03-2024.csv"}; !slices.Equal(got, want) {
+		t.Errorf("files after rollover = %q, want %q", got, want)
+	}
+}
+
+func TestMaxAgeRemovesSeededFiles(t *testing.T) {
+	dir := tempLogDir(t)
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	touch(t, dir,
+		"log-01-02-2024.csv",
+		"log-05-03-2024.jsonl",
+		"log-06-03-2024.csv",
+		"log-08-03-2024.csv",
+		// malformed names are left alone
+		"log-99-99-2024.csv",
+		"log-01-02-2024.csv.bak",
+		"log-1-2-2024.csv",
+	)
+	var logged strings.Builder
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+	l := NewLogger("retention", "1", WithSilentConsole(),
+		WithClock(func() time.Time { return now }), WithMaxAge(3*24*time.Hour))
+	l.Close()
+	if !strings.Contains(logged.String(), "removed 3 log file(s)") {
+		t.Errorf("logged %q, want the number of files removed", logged.String())
+	}
+	want := []string{
+		"log-01-02-2024.csv.bak",
+		"log-08-03-2024.csv",
+		"log-1-2-2024.csv",
+		"log-10-03-2024.csv",
+		"log-99-99-2024.csv",
+	}
+	if got := listDir(t, dir); !slices.Equal(got, want) {
+		t.Errorf("files = %q\nwant %q", got, want)
+	}
+}
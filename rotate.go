@@ -0,0 +1,175 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// return the path of the log file for the given day, including the
+// component in the name if set
+func logFilePath(logDir string, component string, t time.Time, ext string) string {
+	return filepath.Join(logDir, logFilePrefix(component)+formatDate(t)+ext)
+}
+
+// return the start of log file names for a component: log- or log-component-
+func logFilePrefix(component string) string {
+	if component == "" {
+		return "log-"
+	}
+	return "log-" + component + "-"
+}
+
+// make a component name safe to use in a file name. it's lowercased so
+// names differing only in case share a file on case insensitive file
+// systems, and anything other than ASCII letters, digits, '-', and '_' is
+// replaced with an underscore.
+func fileComponent(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(name))
+}
+
+// return the start of the day following t
+func nextMidnight(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+}
+
+// create the log file if it doesn't already exist, then open it for appending.
+// the file mode only applies when the file is created; existing files keep
+// their current permissions, and must have the expected header.
+func (o *output) openFile(logFile string) (*os.File, error) {
+	if err := o.checkHeader(logFile); err != nil {
+		return nil, err
+	}
+	if err := o.createLogFile(logFile); err != nil {
+		return nil, fmt.Errorf("failed to create log file %q: %w", logFile, err)
+	}
+	flag := os.O_APPEND | os.O_WRONLY | os.O_CREATE
+	if o.syncWrites {
+		flag |= os.O_SYNC
+	}
+	file, err := os.OpenFile(logFile, flag, filePerm(o.fileMode))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file %q: %w", logFile, err)
+	}
+	return file, nil
+}
+
+// rollover switches to a new log file once the day has changed.
+// the new file is opened before the current one is closed so no entries
+// are lost if the switch fails; in that case the logger keeps writing
+// to the current file and tries again on the next call.
+// must be called while holding o.mu.
+func (o *output) rollover(now time.Time) {
+	logFile := logFilePath(o.dir, o.fileComponent, now, o.ext())
+	file, err := o.openFile(logFile)
+	if err != nil {
+		log.Printf("failed to roll over log file: %v", err)
+		return
+	}
+	if err := o.flush(); err != nil {
+		log.Printf("failed to flush log file %q: %v", o.path, err)
+	}
+	if err := o.closeFile(); err != nil {
+		log.Printf("failed to close log file %q: %v", o.path, err)
+	}
+	o.setFile(file)
+	o.compress(o.path)
+	o.path = logFile
+	o.nextDay = nextMidnight(now)
+	o.cleanup(now)
+}
+
+// return the path of the nth rotated backup of a log file.
+// ex: log-dd-mm-yyyy.csv -> log-dd-mm-yyyy.1.csv, log-dd-mm-yyyy.csv.gz -> log-dd-mm-yyyy.1.csv.gz
+func backupPath(logFile string, n int) string {
+	ext := filepath.Ext(logFile)
+	if ext == ".gz" {
+		ext = filepath.Ext(strings.TrimSuffix(logFile, ext)) + ext
+	}
+	return fmt.Sprintf("%s.%d%s", strings.TrimSuffix(logFile, ext), n, ext)
+}
+
+// shift existing backups of a log file up by one to make room for a new
+// first backup, removing any beyond maxBackups.
+func shiftBackups(logFile string, maxBackups int) error {
+	last := 0
+	for {
+		if _, err := os.Stat(backupPath(logFile, last+1)); err != nil {
+			break
+		}
+		last++
+	}
+	for n := last; n >= 1; n-- {
+		if maxBackups > 0 && n >= maxBackups {
+			if err := os.Remove(backupPath(logFile, n)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.Rename(backupPath(logFile, n), backupPath(logFile, n+1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rotate moves the current log file to a numbered backup, such as
+// log-dd-mm-yyyy.1.csv, and starts a new one in its place, regardless of
+// WithMaxSize. Any queued or buffered entries are written to the current
+// file first. Backups are numbered and limited by WithMaxBackups as with
+// size based rotation. If the file can't be moved, the logger keeps
+// appending to it and the error is returned. Rotate returns ErrClosed if
+// the logger has been closed.
+func (l *Logger) Rotate() error {
+	o := l.out
+	if o.async != nil {
+		o.async.wait()
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed || l.ref.closed.Load() {
+		return ErrClosed
+	} else if o.file == nil {
+		return ErrNoFile
+	}
+	o.flushRepeats()
+	return o.rotate()
+}
+
+// rotate moves the current log file to a numbered backup and starts a
+// new one in its place. if the file can't be moved, the logger keeps
+// appending to it and the error is returned.
+// must be called while holding o.mu.
+func (o *output) rotate() error {
+	if err := o.flush(); err != nil {
+		log.Printf("failed to flush log file %q: %v", o.path, err)
+	}
+	if err := o.closeFile(); err != nil {
+		log.Printf("failed to close log file %q: %v", o.path, err)
+	}
+	err := shiftBackups(o.path, o.maxBackups)
+	if err == nil {
+		err = os.Rename(o.path, backupPath(o.path, 1))
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to rotate log file %q: %w", o.path, err)
+	}
+	// reopen the active path, which creates a new file with a header
+	// if the old one was moved.
+	file, oerr := o.openFile(o.path)
+	if oerr != nil {
+		return errors.Join(err, oerr)
+	}
+	o.setFile(file)
+	return err
+}
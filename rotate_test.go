@@ -0,0 +1,239 @@
+package logger
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRolloverAtMidnightInTimeZone(t *testing.T) {
+	for _, loc := range []*time.Location{time.UTC, time.FixedZone("UTC+5", 5*3600), time.FixedZone("UTC-8", -8*3600)} {
+		dir := tempLogDir(t)
+		// a second before midnight in loc
+		now := time.Date(2024, 3, 10, 23, 59, 59, 0, loc)
+		l := NewLogger("midnight", "1", WithSilentConsole(), WithTimeZone(loc),
+			WithClock(func() time.Time { return now.UTC() }))
+		l.Info("before midnight")
+		before := l.FilePath()
+		now = now.Add(2 * time.Second)
+		l.Info("after midnight")
+		after := l.FilePath()
+
+		if want := filepath.Join(dir, "log-10-03-2024.csv"); before != want {
+			t.Errorf("%s: file before midnight = %s, want %s", loc, before, want)
+		}
+		if want := filepath.Join(dir, "log-11-03-2024.csv"); after != want {
+			t.Errorf("%s: file after midnight = %s, want %s", loc, after, want)
+		}
+		// rows are in the file for the day of their timestamp in loc
+		for path, day := range map[string]int{before: 10, after: 11} {
+			entries, err := ReadEntries(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || entries[0].Time.In(loc).Day() != day {
+				t.Errorf("%s: %s has %+v, want one entry from day %d", loc, filepath.Base(path), entries, day)
+			}
+		}
+		l.Close()
+	}
+}
+
+// a clock fixed at noon on 2024-03-10 UTC
+func fixedClock() time.Time {
+	return time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+}
+
+func TestManualRotate(t *testing.T) {
+	dir := tempLogDir(t)
+	l := NewLogger("rotate", "1", WithSilentConsole(), WithClock(fixedClock))
+	defer l.Close()
+	l.Info("before")
+	if err := l.Rotate(); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	l.Info("after")
+
+	want := []string{"log-10-03-2024.1.csv", "log-10-03-2024.csv"}
+	if got := listDir(t, dir); !slices.Equal(got, want) {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+	if entries := readLog(t, l); len(entries) != 1 || entries[0].Message != "after" {
+		t.Errorf("new file has %+v, want only the entry after Rotate", entries)
+	}
+	backup, err := ReadEntries(filepath.Join(dir, want[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backup) != 1 || backup[0].Message != "before" {
+		t.Errorf("backup has %+v, want only the entry before Rotate", backup)
+	}
+}
+
+func TestManualRotateWritesBufferedEntries(t *testing.T) {
+	dir := tempLogDir(t)
+	l := NewLogger("rotate", "1", WithSilentConsole(), WithClock(fixedClock),
+		WithAutoFlush(false), WithAsync(64))
+	defer l.Close()
+	for range 10 {
+		l.Info("queued")
+	}
+	if err := l.Rotate(); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	backup, err := ReadEntries(filepath.Join(dir, "log-10-03-2024.1.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backup) != 10 {
+		t.Errorf("backup has %d entries, want the 10 logged before Rotate", len(backup))
+	}
+	if entries := readLog(t, l); len(entries) != 0 {
+		t.Errorf("new file has %d entries, want none", len(entries))
+	}
+}
+
+func TestManualRotateKeepsMaxBackups(t *testing.T) {
+	dir := tempLogDir(t)
+	l := NewLogger("rotate", "1", WithSilentConsole(), WithClock(fixedClock), WithMaxBackups(2))
+	defer l.Close()
+	for range 4 {
+		l.Info("entry")
+		if err := l.Rotate(); err != nil {
+			t.Fatalf("Rotate: %v", err)
+		}
+	}
+	want := []string{"log-10-03-2024.1.csv", "log-10-03-2024.2.csv", "log-10-03-2024.csv"}
+	if got := listDir(t, dir); !slices.Equal(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
+
+// loggers sharing a file all move on to the new one
+func TestManualRotateSharedOutput(t *testing.T) {
+	tempLogDir(t)
+	a := NewLogger("rotate", "1", WithSilentConsole(), WithClock(fixedClock))
+	defer a.Close()
+	b := NewLogger("rotate", "2", WithSilentConsole(), WithClock(fixedClock))
+	defer b.Close()
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				b.Info("concurrent")
+			}
+		}()
+	}
+	for range 5 {
+		if err := a.Rotate(); err != nil {
+			t.Errorf("Rotate: %v", err)
+		}
+	}
+	wg.Wait()
+	if err := a.Rotate(); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	b.Info("after")
+	if entries := readLog(t, a); len(entries) != 1 || entries[0].ID != "2" {
+		t.Errorf("new file has %+v, want the entry b logged after Rotate", entries)
+	}
+}
+
+func TestManualRotateAfterClose(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("rotate", "1", WithSilentConsole())
+	l.Close()
+	if err := l.Rotate(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Rotate after Close = %v, want ErrClosed", err)
+	}
+}
+
+// the number of entries in each of the named files in dir
+func entriesPerFile(t *testing.T, dir string, names ...string) []int {
+	t.Helper()
+	counts := make([]int, len(names))
+	for i, name := range names {
+		entries, err := ReadEntries(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[i] = len(entries)
+	}
+	return counts
+}
+
+func TestMaxLines(t *testing.T) {
+	dir := tempLogDir(t)
+	l := NewLogger("lines", "1", WithSilentConsole(), WithClock(fixedClock), WithMaxLines(3))
+	for i := range 10 {
+		// line breaks in a message don't count as extra rows
+		l.Info("entry %d\nsecond line", i)
+	}
+	l.Close()
+
+	files := []string{"log-10-03-2024.1.csv", "log-10-03-2024.2.csv", "log-10-03-2024.3.csv", "log-10-03-2024.csv"}
+	if got := listDir(t, dir); !slices.Equal(got, files) {
+		t.Fatalf("files = %v, want %v", got, files)
+	}
+	if got, want := entriesPerFile(t, dir, files...), []int{3, 3, 3, 1}; !slices.Equal(got, want) {
+		t.Errorf("entries per file = %v, want %v", got, want)
+	}
+	// .1 is the most recent backup
+	newest, err := ReadEntries(filepath.Join(dir, files[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newest[0].Message != "entry 6\nsecond line" {
+		t.Errorf(".1 starts with %q, want entry 6", newest[0].Message)
+	}
+}
+
+// whichever of the size and line limits is reached first rotates the file
+func TestMaxLinesWithMaxSize(t *testing.T) {
+	dir := tempLogDir(t)
+	l := NewLogger("lines", "1", WithSilentConsole(), WithClock(fixedClock), WithMaxLines(10), WithMaxSize(200))
+	for range 4 {
+		l.Infoln("short")
+	}
+	l.Infoln(strings.Repeat("long ", 40))
+	l.Infoln("after the size limit")
+	l.Close()
+
+	files := []string{"log-10-03-2024.1.csv", "log-10-03-2024.csv"}
+	if got := listDir(t, dir); !slices.Equal(got, files) {
+		t.Fatalf("files = %v, want %v", got, files)
+	}
+	if got, want := entriesPerFile(t, dir, files...), []int{5, 1}; !slices.Equal(got, want) {
+		t.Errorf("entries per file = %v, want %v", got, want)
+	}
+}
+
+// the count starts again with each day's file
+func TestMaxLinesResetsOnRollover(t *testing.T) {
+	dir := tempLogDir(t)
+	now := time.Date(2024, 3, 10, 23, 59, 0, 0, time.UTC)
+	l := NewLogger("lines", "1", WithSilentConsole(), WithMaxLines(3),
+		WithClock(func() time.Time { return now }))
+	l.Infoln("first")
+	l.Infoln("second")
+	now = now.Add(2 * time.Minute)
+	for range 3 {
+		l.Infoln("next day")
+	}
+	l.Close()
+
+	files := []string{"log-10-03-2024.csv", "log-11-03-2024.csv"}
+	if got := listDir(t, dir); !slices.Equal(got, files) {
+		t.Fatalf("files = %v, want %v", got, files)
+	}
+	if got, want := entriesPerFile(t, dir, files...), []int{2, 3}; !slices.Equal(got, want) {
+		t.Errorf("entries per file = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,61 @@
+package logger
+
+import "strings"
+
+// replaces line breaks with escape sequences so each entry stays on one line
+var newlineEscaper = strings.NewReplacer("\n", `\n`, "\r", `\r`)
+
+// characters that cause spreadsheet applications to treat a
+// cell as a formula. tab and carriage return are included since
+// some applications strip them before evaluating the cell.
+func isFormulaPrefix(c byte) bool {
+	switch c {
+	case '=', '+', '-', '@', '\t', '\r':
+		return true
+	}
+	return false
+}
+
+// neutralize a field that would otherwise be evaluated as a formula
+// by prefixing it with a single quote. fields that already start with a
+// quote that would be removed when reading them back get another one, so
+// the original field can always be recovered.
+func sanitizeField(field string) string {
+	if len(field) > 0 && isFormulaPrefix(field[0]) || quoted(field) {
+		return "'" + field
+	}
+	return field
+}
+
+// reverse sanitizeField by removing the quote it added
+func unsanitizeField(field string) string {
+	if quoted(field) {
+		return field[1:]
+	}
+	return field
+}
+
+// reports whether a field starts with a quote added by sanitizeField
+func quoted(field string) bool {
+	return len(field) > 1 && field[0] == '\'' && (isFormulaPrefix(field[1]) || field[1] == '\'')
+}
+
+// build a csv row from the given fields, escaping newlines and
+// sanitizing them if enabled. the fields are in the order of the
+// configured columns.
+func (l *Logger) row(fields ...string) []string {
+	for i, f := range fields {
+		if l.out.columns[i].time {
+			// formatted by the logger, so it never needs escaping
+			continue
+		}
+		if l.escapeNewlines {
+			f = newlineEscaper.Replace(f)
+		}
+		if l.sanitize {
+			f = sanitizeField(f)
+		}
+		fields[i] = f
+	}
+	return fields
+}
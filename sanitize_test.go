@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+// the csv records written by a buffer logger, without the header
+func bufferRecords(t *testing.T, data string) [][]string {
+	t.Helper()
+	return bufferRecordsComma(t, data, ',')
+}
+
+// like bufferRecords, for records written with a custom delimiter
+func bufferRecordsComma(t *testing.T, data string, comma rune) [][]string {
+	t.Helper()
+	r := csv.NewReader(strings.NewReader(data))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("invalid csv: %v\n%s", err, data)
+	}
+	return records[1:]
+}
+
+func TestSanitizeFormula(t *testing.T) {
+	const formula = `=cmd|'/c calc'!A1`
+	for _, msg := range []string{formula, "+1", "-1", "@SUM(A1)"} {
+		l, buf := NewBufferLogger("sanitize", "1", WithSilentConsole())
+		l.Infoln(msg)
+		l.Close()
+		if got := bufferRecords(t, buf.String())[0][3]; got != "'"+msg {
+			t.Errorf("message %q written as %q, want it prefixed with a quote", msg, got)
+		}
+	}
+
+	l, buf := NewBufferLogger("sanitize", "1", WithSilentConsole(), WithSanitizeCSV(false))
+	l.Infoln(formula)
+	l.Close()
+	if got := bufferRecords(t, buf.String())[0][3]; got != formula {
+		t.Errorf("with sanitizing disabled, message written as %q", got)
+	}
+}
+
+func TestSanitizeRoundTrip(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("sanitize", "1", WithSilentConsole())
+	defer l.Close()
+	msgs := []string{
+		"=cmd|'/c calc'!A1 with \"quotes\", commas\nand newlines",
+		"'=x",
+		"''=x",
+		"'quoted'",
+		"'",
+	}
+	for _, msg := range msgs {
+		l.Infoln(msg)
+	}
+	entries := readLog(t, l)
+	if len(entries) != len(msgs) {
+		t.Fatalf("read back %d entries, want %d", len(entries), len(msgs))
+	}
+	for i, msg := range msgs {
+		if entries[i].Message != msg {
+			t.Errorf("message %q read back as %q", msg, entries[i].Message)
+		}
+	}
+}
+
+func TestSanitizeQuotedMessage(t *testing.T) {
+	for msg, want := range map[string]string{
+		"'=x":      "''=x",
+		"'quoted'": "'quoted'",
+	} {
+		l, buf := NewBufferLogger("sanitize", "1", WithSilentConsole())
+		l.Infoln(msg)
+		l.Close()
+		if got := bufferRecords(t, buf.String())[0][3]; got != want {
+			t.Errorf("message %q written as %q, want %q", msg, got, want)
+		}
+	}
+}
+
+func TestEscapeNewlines(t *testing.T) {
+	trace := "panic: boom\n\ngoroutine 1 [running]:\r\nmain.main()\n\t/app/main.go:12 +0x1d"
+	l, buf := NewBufferLogger("escape", "1", WithSilentConsole(), WithEscapeNewlines(true))
+	l.ErrorWith(map[string]any{"stack": trace}, "%s", trace)
+	l.Close()
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want the header and one entry:\n%s", len(lines), buf.String())
+	}
+	want := `panic: boom\n\ngoroutine 1 [running]:\r\nmain.main()\n` + "\t/app/main.go:12 +0x1d"
+	if got := bufferRecords(t, buf.String())[0][3]; got != want {
+		t.Errorf("message written as %q, want %q", got, want)
+	}
+}
+
+func TestNewlinesKeptByDefault(t *testing.T) {
+	l, buf := NewBufferLogger("escape", "1", WithSilentConsole())
+	l.Infoln("line one\nline two")
+	l.Close()
+	if got := bufferRecords(t, buf.String())[0][3]; got != "line one\nline two" {
+		t.Errorf("message written as %q", got)
+	}
+}
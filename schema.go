@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// Column is a column in csv log files. The built-in columns are provided
+// as ColumnTime, ColumnComponent, and so on. Custom columns can be added
+// with a function computing the column's value for each entry:
+//
+//	region := logger.Column{Name: "Region", Value: func(logger.Entry) string { return os.Getenv("REGION") }}
+//	l := logger.NewLogger("api", "", logger.WithColumns(append(logger.DefaultColumns(), region)...))
+type Column struct {
+	Name  string             // column name written in the header
+	Value func(Entry) string // value of the column for an entry
+	time  bool               // whether this is the built-in time column
+	host  bool               // whether this is the built-in host column
+}
+
+// Built-in columns
+var (
+	ColumnTime      = Column{Name: "Time", time: true}
+	ColumnComponent = Column{Name: "Component", Value: func(e Entry) string { return e.Component }}
+	ColumnLevel     = Column{Name: "Level", Value: func(e Entry) string { return e.Level }}
+	ColumnMessage   = Column{Name: "Message", Value: func(e Entry) string { return e.Message }}
+	ColumnID        = Column{Name: "ID", Value: func(e Entry) string { return e.ID }}
+	ColumnTaskID    = Column{Name: "TaskID", Value: func(e Entry) string { return e.TaskID }}
+	ColumnHost      = Column{Name: "Host", Value: func(Entry) string { return hostname() }, host: true}
+	ColumnPID       = Column{Name: "PID", Value: func(Entry) string { return pid }}
+)
+
+// names of the columns written after the configured ones
+const (
+	seqColumn    = "Seq"
+	sourceColumn = "Source"
+	extraColumn  = "Extra"
+)
+
+// WithSequence numbers entries with a sequence number, increasing by one
+// for every entry written to the log file, so dropped or reordered lines
+// can be detected. It's written in a Seq column after the configured
+// columns in csv log files, as "seq" in json log files, and set as the
+// Seq of entries passed to hooks, sinks, and formatters. Numbers are
+// shared by every logger writing to the same file. With WithAsync, entries
+// are numbered when they're queued, so entries dropped because the buffer
+// is full leave gaps. Entries held back by WithDedup leave gaps too, since
+// only the last repeat's number is written. Disabled by default.
+func WithSequence(enabled bool) Option {
+	return func(l *Logger) {
+		l.out.sequence = enabled
+	}
+}
+
+// WithHostname writes the name of the machine with each entry, so entries
+// from multiple hosts writing to shared storage can be told apart. It's
+// written in a Host column after the configured columns in csv log files,
+// unless WithColumns already includes ColumnHost, and as "host" in json
+// log files. The name is looked up once when the logger is created, and
+// is empty if the lookup fails. See WithHostnameOverride to set it
+// explicitly. Disabled by default.
+func WithHostname(enabled bool) Option {
+	return func(l *Logger) {
+		l.out.hostname = enabled
+	}
+}
+
+// WithHostnameOverride sets the host name written by WithHostname and
+// ColumnHost instead of looking it up, such as a pod or instance name.
+func WithHostnameOverride(name string) Option {
+	return func(l *Logger) {
+		l.out.host = name
+	}
+}
+
+// WithExtraColumn adds an Extra column to csv log files holding each
+// entry's fields as a JSON object, left empty for entries without fields.
+// Without it, fields are written in an unnamed column that's only present
+// on rows with fields. ReadEntries parses the Extra column into the
+// entries' Fields. Disabled by default.
+func WithExtraColumn(enabled bool) Option {
+	return func(l *Logger) {
+		l.out.extraColumn = enabled
+	}
+}
+
+// DefaultColumns returns the columns written when none are configured:
+// Time, Component, Level, Message, and ID.
+func DefaultColumns() []Column {
+	return []Column{ColumnTime, ColumnComponent, ColumnLevel, ColumnMessage, ColumnID}
+}
+
+// WithColumns sets the ordered columns written to csv log files, and the
+// header written when a log file is created. Defaults to DefaultColumns.
+// The Source column (see WithSource) and structured fields are written
+// after the configured columns.
+func WithColumns(cols ...Column) Option {
+	return func(l *Logger) {
+		l.out.columns = cols
+	}
+}
+
+var pid = strconv.Itoa(os.Getpid())
+
+// hostname of the machine, looked up once
+var hostname = sync.OnceValue(func() string {
+	name, _ := os.Hostname()
+	return name
+})
+
+// add the host column if host names are written and the configured columns
+// don't already include it
+func (o *output) addHostColumn() {
+	if !o.hostname || o.format != FormatCSV || slices.ContainsFunc(o.columns, func(c Column) bool { return c.host }) {
+		return
+	}
+	o.columns = append(slices.Clip(o.columns), ColumnHost)
+}
+
+// host name written in json log files, empty unless WithHostname is enabled
+func (o *output) jsonHost() string {
+	if !o.hostname {
+		return ""
+	}
+	return o.host
+}
+
+// column names for the given columns
+func columnNames(cols []Column) []string {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// build a csv record for an entry using the configured columns. the
+// timestamp has already been formatted for the time column.
+func (o *output) csvRecord(e Entry, timestamp string) []string {
+	record := make([]string, len(o.columns))
+	for i, c := range o.columns {
+		switch {
+		case c.time:
+			record[i] = timestamp
+		case c.host:
+			record[i] = o.host
+		case c.Value != nil:
+			record[i] = c.Value(e)
+		}
+	}
+	return record
+}
@@ -0,0 +1,211 @@
+package logger
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// log from several goroutines through l and a logger sharing its file
+func logConcurrently(l, other *Logger, goroutines, perGoroutine int) {
+	var wg sync.WaitGroup
+	for i := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger := l
+			if i%2 == 1 {
+				logger = other
+			}
+			for range perGoroutine {
+				logger.Infoln("entry")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// reports whether seqs are 1 through n in order
+func contiguous(seqs []uint64, n int) bool {
+	if len(seqs) != n {
+		return false
+	}
+	for i, seq := range seqs {
+		if seq != uint64(i+1) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSequenceContiguous(t *testing.T) {
+	for _, format := range []Format{FormatCSV, FormatJSON} {
+		tempLogDir(t)
+		sink := &memSink{}
+		l := NewLogger("seq", "1", WithSilentConsole(), WithSequence(true), WithFormat(format), WithSinks(sink))
+		other := NewLogger("seq", "2", WithSilentConsole(), WithSequence(true), WithFormat(format))
+		logConcurrently(l, other, 8, 100)
+		entries := readLog(t, l)
+		other.Close()
+		l.Close()
+
+		var seqs []uint64
+		for _, e := range entries {
+			seqs = append(seqs, e.Seq)
+		}
+		// numbers are assigned under the file's lock, so they're in the
+		// order the entries were written
+		if !contiguous(seqs, 800) {
+			t.Errorf("format %v: log file sequence numbers aren't 1 to 800 in order: %v", format, seqs[:min(len(seqs), 20)])
+		}
+
+		sink.mu.Lock()
+		var sunk []uint64
+		for _, e := range sink.entries {
+			sunk = append(sunk, e.Seq)
+		}
+		sink.mu.Unlock()
+		// the sink only gets l's entries, numbered the same as in the file
+		slices.Sort(sunk)
+		if len(sunk) != 400 || sunk[0] < 1 || sunk[len(sunk)-1] > 800 || len(slices.Compact(sunk)) != 400 {
+			t.Errorf("format %v: sink got %d entries, want 400 with distinct sequence numbers from 1 to 800", format, len(sunk))
+		}
+	}
+}
+
+func TestSequenceContiguousAsync(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("seq", "1", WithSilentConsole(), WithSequence(true), WithAsync(16))
+	other := l.Child("other")
+	logConcurrently(l, other, 8, 100)
+	entries := readLog(t, l)
+	l.Close()
+
+	var seqs []uint64
+	for _, e := range entries {
+		seqs = append(seqs, e.Seq)
+	}
+	// numbers are assigned when entries are queued, in queue order
+	if !contiguous(seqs, 800) {
+		t.Errorf("log file sequence numbers aren't 1 to 800 in order: %v", seqs[:min(len(seqs), 20)])
+	}
+}
+
+func TestSequenceDisabled(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("seq", "1", WithSilentConsole())
+	defer l.Close()
+	l.Infoln("entry")
+	if entries := readLog(t, l); len(entries) != 1 || entries[0].Seq != 0 {
+		t.Errorf("entries = %+v, want one without a sequence number", entries)
+	}
+}
+
+// the header and rows of a csv log file
+func csvRows(t *testing.T, path string) (header []string, rows [][]string) {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.Comment = '#' // the schema version line
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil || len(records) == 0 {
+		t.Fatalf("invalid log file: %v", err)
+	}
+	return records[0], records[1:]
+}
+
+func TestHostnameOverride(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("host", "1", WithSilentConsole(), WithHostname(true), WithHostnameOverride("pod-7"))
+	defer l.Close()
+	l.Infoln("first")
+	l.Child("child").Infoln("second")
+	l.Flush()
+
+	header, rows := csvRows(t, l.FilePath())
+	if want := []string{"Time", "Component", "Level", "Message", "ID", "Host"}; !slices.Equal(header, want) {
+		t.Errorf("header = %v, want %v", header, want)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("wrote %d rows, want 2", len(rows))
+	}
+	for _, row := range rows {
+		if row[5] != "pod-7" {
+			t.Errorf("row %v has host %q, want pod-7", row, row[5])
+		}
+	}
+}
+
+func TestHostnameLookedUp(t *testing.T) {
+	want, err := os.Hostname()
+	if err != nil {
+		t.Skip("no host name:", err)
+	}
+	tempLogDir(t)
+	l := NewLogger("host", "1", WithSilentConsole(), WithHostname(true))
+	defer l.Close()
+	l.Infoln("entry")
+	l.Flush()
+	if _, rows := csvRows(t, l.FilePath()); rows[0][5] != want {
+		t.Errorf("host = %q, want %q", rows[0][5], want)
+	}
+}
+
+// a Host column in the configured columns is used rather than adding
+// another, and it's given the override too
+func TestHostnameColumnConfigured(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("host", "1", WithSilentConsole(), WithHostname(true), WithHostnameOverride("pod-7"),
+		WithColumns(ColumnTime, ColumnHost, ColumnMessage))
+	defer l.Close()
+	l.Infoln("entry")
+	l.Flush()
+	header, rows := csvRows(t, l.FilePath())
+	if want := []string{"Time", "Host", "Message"}; !slices.Equal(header, want) {
+		t.Errorf("header = %v, want %v", header, want)
+	}
+	if rows[0][1] != "pod-7" {
+		t.Errorf("host = %q, want pod-7", rows[0][1])
+	}
+}
+
+func TestHostnameJSON(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("host", "1", WithSilentConsole(), WithFormat(FormatJSON), WithHostname(true),
+		WithHostnameOverride("pod-7"))
+	defer l.Close()
+	l.Infoln("entry")
+	l.Flush()
+	data, err := os.ReadFile(l.FilePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var line map[string]any
+	if err := json.Unmarshal(data, &line); err != nil {
+		t.Fatal(err)
+	}
+	if line["host"] != "pod-7" {
+		t.Errorf("host = %v, want pod-7", line["host"])
+	}
+}
+
+func TestHostnameDisabled(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("host", "1", WithSilentConsole(), WithHostnameOverride("pod-7"))
+	defer l.Close()
+	l.Infoln("entry")
+	l.Flush()
+	header, rows := csvRows(t, l.FilePath())
+	if slices.Contains(header, "Host") || strings.Contains(strings.Join(rows[0], ","), "pod-7") {
+		t.Errorf("header %v and row %v include the host without WithHostname", header, rows[0])
+	}
+}
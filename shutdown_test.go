@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShutdown(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("shutdown", "1", WithSilentConsole(), WithAsync(64), WithAutoFlush(false))
+	for range 10 {
+		l.Infoln("entry")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := l.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	entries, err := ReadEntries(l.FilePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 10 {
+		t.Errorf("log file has %d entries, want all 10 written", len(entries))
+	}
+	if err := l.Write(Entry{Level: INFO, Message: "after shutdown"}); !errors.Is(err, ErrClosed) {
+		t.Errorf("logging after Shutdown = %v, want ErrClosed", err)
+	}
+}
+
+func TestShutdownCancelled(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("shutdown", "1", WithSilentConsole(), WithAsync(64))
+
+	// a write that never completes, like one to a hung disk
+	l.out.mu.Lock()
+	for range 10 {
+		l.Infoln("stuck")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	err := l.Shutdown(ctx)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Shutdown took %s with a cancelled context", elapsed)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Shutdown = %v, want context.Canceled", err)
+	}
+	// one entry may already be with the writer, waiting on the lock
+	if err == nil || !strings.Contains(err.Error(), "entries still queued") {
+		t.Errorf("Shutdown = %v, want it to report the queued entries", err)
+	}
+	if err := l.Write(Entry{Level: INFO, Message: "after shutdown"}); !errors.Is(err, ErrClosed) {
+		t.Errorf("logging after Shutdown = %v, want ErrClosed", err)
+	}
+
+	// closing carries on once the write completes
+	l.out.mu.Unlock()
+	if err := l.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	l.out.async.done.Wait()
+	l.out.mu.Lock()
+	closed := l.out.closed
+	l.out.mu.Unlock()
+	if !closed {
+		t.Error("output wasn't closed after the stuck write completed")
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("shutdown", "1", WithSilentConsole(), WithAsync(64))
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	for range 10 {
+		l.Infoln("stuck")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := l.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,243 @@
+package logger
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sink is a destination for log entries in addition to the log file,
+// such as a network collector or an in-memory buffer in tests. Each sink
+// receives the structured entry and formats it itself.
+type Sink interface {
+	// WriteEntry writes a single entry.
+	WriteEntry(e Entry) error
+	// Close flushes any pending entries and releases the sink's resources.
+	Close() error
+}
+
+// size of the queue of entries waiting to be written to each sink
+const sinkQueueSize = 1024
+
+// WithSinks sends every entry written to the log file to the given sinks
+// as well. Each sink is written to on its own goroutine, so a slow or
+// failing sink doesn't block logging or the other sinks. Entries are
+// dropped if a sink falls too far behind. Sink errors are logged, and
+// sinks are closed when the logger is closed. Sinks belong to the logger
+// they're given to and the loggers derived from it, so they don't receive
+// entries from other loggers writing to the same log file.
+func WithSinks(sinks ...Sink) Option {
+	return WithSinksLevel("", sinks...)
+}
+
+// WithSinksLevel is like WithSinks, but only sends entries at or above
+// level to the given sinks, such as to send only warnings and errors to a
+// remote collector while the log file keeps everything. The logger's own
+// minimum level is checked first, so sinks never receive entries below it
+// even if their level is lower. Entries at unregistered levels are always
+// sent. An empty or unregistered level sends every entry.
+func WithSinksLevel(level string, sinks ...Sink) Option {
+	return func(l *Logger) {
+		sev, ok := parseLevel(level)
+		if !ok {
+			sev = math.MinInt
+		}
+		for _, s := range sinks {
+			l.ref.sinks = append(l.ref.sinks, &sinkRunner{sink: s, level: sev})
+		}
+	}
+}
+
+// the sinks of a logger, shared with derived loggers. sinks belong to the
+// logger that was given them rather than its output, so they're started
+// and closed along with it even when it shares its log file.
+type sinkSet []*sinkRunner
+
+// sinkRunner writes entries to a sink on a background goroutine.
+type sinkRunner struct {
+	sink    Sink
+	level   int // minimum severity of entries sent to the sink
+	entries chan Entry
+	dropped atomic.Uint64 // entries dropped because the queue was full or the sink failed without counting them
+	mu      sync.RWMutex  // guards closed so entries aren't sent once entries is closed
+	closed  bool
+	done    chan struct{} // closed once the goroutine exits
+}
+
+// start writing entries to the sinks
+func (s sinkSet) start() {
+	for _, r := range s {
+		r.entries = make(chan Entry, sinkQueueSize)
+		r.done = make(chan struct{})
+		go r.run()
+	}
+}
+
+func (r *sinkRunner) run() {
+	defer close(r.done)
+	// sinks that count their own dropped entries, such as HTTPSink, may
+	// drop more than the entry that failed, so only panics are counted
+	// for them here
+	_, counted := r.sink.(interface{ Dropped() uint64 })
+	failing := false
+	for e := range r.entries {
+		panicked, err := r.write(e)
+		if err != nil && (panicked || !counted) {
+			r.dropped.Add(1)
+		}
+		switch {
+		case err != nil && !failing:
+			// only log the first of a run of errors so a sink that's
+			// down doesn't flood the console
+			log.Printf("failed to write to log sink %T: %v", r.sink, err)
+			failing = true
+		case err == nil && failing:
+			log.Printf("log sink %T recovered", r.sink)
+			failing = false
+		}
+	}
+}
+
+// write an entry to the sink, recovering from any panic
+func (r *sinkRunner) write(e Entry) (panicked bool, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			panicked, err = true, fmt.Errorf("panic: %v\n%s", v, debug.Stack())
+		}
+	}()
+	return false, r.sink.WriteEntry(e)
+}
+
+// queue an entry for the sink, dropping it if the queue is full
+func (r *sinkRunner) send(e Entry) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return
+	}
+	select {
+	case r.entries <- e:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// write queued entries and close the sink
+func (r *sinkRunner) close() error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.entries)
+	}
+	r.mu.Unlock()
+	<-r.done
+	return r.sink.Close()
+}
+
+// send an entry to all sinks. must not be called while holding o.mu.
+func (s sinkSet) send(e Entry) {
+	sev, known := parseLevel(e.Level)
+	for _, r := range s {
+		if !known || sev >= r.level {
+			r.send(e)
+		}
+	}
+}
+
+// close all sinks, returning the first error
+func (s sinkSet) close() error {
+	var err error
+	for _, r := range s {
+		if cerr := r.close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// NewCSVSink returns a sink that writes entries to w as csv rows with the
+// default columns, followed by the entry's fields as JSON if it has any.
+func NewCSVSink(w io.Writer) Sink {
+	return &csvSink{w: csv.NewWriter(w)}
+}
+
+type csvSink struct {
+	mu sync.Mutex
+	w  *csv.Writer
+}
+
+func (s *csvSink) WriteEntry(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record := []string{e.Time.Format(time.RFC3339), e.Component, e.Level, e.Message, e.ID}
+	if len(e.Fields) > 0 {
+		record = append(record, encodeFields(e.Fields))
+	}
+	if err := s.w.Write(record); err != nil {
+		return err
+	}
+	s.w.Flush()
+	return s.w.Error()
+}
+
+func (s *csvSink) Close() error { return nil }
+
+// NewJSONSink returns a sink that writes entries to w as newline delimited
+// JSON objects, in the same form as FormatJSON log files.
+func NewJSONSink(w io.Writer) Sink {
+	return &jsonSink{enc: json.NewEncoder(w)}
+}
+
+type jsonSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func (s *jsonSink) WriteEntry(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(toJSONEntry(e))
+}
+
+// convert an entry to its JSON form
+func toJSONEntry(e Entry) jsonEntry {
+	return jsonEntry{
+		Time:      e.Time.Format(time.RFC3339),
+		Component: e.Component,
+		Level:     e.Level,
+		Message:   e.Message,
+		ID:        e.ID,
+		TaskID:    e.TaskID,
+		Source:    e.Source,
+		Fields:    jsonFields(e.Fields),
+	}
+}
+
+func (s *jsonSink) Close() error { return nil }
+
+// NewWriterSink returns a sink that writes entries to w as plain text
+// lines: time, component, level, message, and ID separated by spaces.
+func NewWriterSink(w io.Writer) Sink {
+	return &writerSink{w: w}
+}
+
+type writerSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *writerSink) WriteEntry(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := fmt.Fprintf(s.w, "%s %s %s %s %s\n", e.Time.Format(time.RFC3339), e.Component, e.Level, e.Message, e.ID)
+	return err
+}
+
+func (s *writerSink) Close() error { return nil }
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSinksLevel(t *testing.T) {
+	if err := RegisterLevel("AUDIT", 6); err != nil {
+		t.Fatal(err)
+	}
+	file, remote := &memSink{}, &memSink{}
+	l, _ := NewBufferLogger("sinks", "1", WithSilentConsole(), WithSinks(file), WithSinksLevel(WARN, remote))
+	l.SetLevel(DEBUG)
+	l.Debugln("debug")
+	l.Infoln("info")
+	l.Warnln("warn")
+	l.Log("AUDIT", "audit")
+	l.Errorln("error")
+	l.LogBatch([]Entry{{Level: INFO, Message: "batch info"}, {Level: ERROR, Message: "batch error"}})
+	l.Close()
+
+	if want := []string{"debug", "info", "warn", "audit", "error", "batch info", "batch error"}; !slices.Equal(file.messages(), want) {
+		t.Errorf("file sink got %q, want %q", file.messages(), want)
+	}
+	if want := []string{"warn", "audit", "error", "batch error"}; !slices.Equal(remote.messages(), want) {
+		t.Errorf("WARN sink got %q, want %q", remote.messages(), want)
+	}
+}
+
+// the logger's level is checked before the sinks' levels
+func TestSinksLevelBelowLoggerLevel(t *testing.T) {
+	debug, remote := &memSink{}, &memSink{}
+	l, _ := NewBufferLogger("sinks", "1", WithSilentConsole(), WithSinksLevel(DEBUG, debug), WithSinksLevel(ERROR, remote))
+	l.SetLevel(WARN)
+	l.Debugln("debug")
+	l.Infoln("info")
+	l.Warnln("warn")
+	l.Errorln("error")
+	l.Close()
+
+	if want := []string{"warn", "error"}; !slices.Equal(debug.messages(), want) {
+		t.Errorf("DEBUG sink got %q, want %q", debug.messages(), want)
+	}
+	if want := []string{"error"}; !slices.Equal(remote.messages(), want) {
+		t.Errorf("ERROR sink got %q, want %q", remote.messages(), want)
+	}
+}
+
+func TestSinksLevelUnregistered(t *testing.T) {
+	sink := &memSink{}
+	l, _ := NewBufferLogger("sinks", "1", WithSilentConsole(), WithSinksLevel("VERBOSE", sink))
+	l.SetLevel(DEBUG)
+	l.Debugln("debug")
+	l.Close()
+	if want := []string{"debug"}; !slices.Equal(sink.messages(), want) {
+		t.Errorf("sink got %q, want every entry", sink.messages())
+	}
+	if !sink.closed {
+		t.Error("sink wasn't closed with the logger")
+	}
+}
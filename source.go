@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+)
+
+// WithSource records the file and line of the code that logged each
+// entry. In csv log files it's written in a Source column after the
+// ID, and it's displayed as the source attribute on the console.
+// Disabled by default since walking the stack has a cost.
+func WithSource(enabled bool) Option {
+	return func(l *Logger) {
+		l.out.addSource = enabled
+	}
+}
+
+// WithCallerSkip skips n more stack frames when recording source locations
+// with WithSource, so code that logs through its own helper functions
+// reports the helper's caller rather than the helper. The default of 0 is
+// right for calling the logger's methods directly. Add one for each layer
+// of wrapping: a helper that calls Info needs 1, and a helper calling that
+// helper needs 2. Loggers derived from this one keep the skip.
+//
+//	func logRequest(l *logger.Logger, r *http.Request) {
+//		l.Info("%s %s", r.Method, r.URL.Path) // reported at logRequest's caller
+//	}
+//
+//	l := logger.NewLogger("api", "1", logger.WithSource(true), logger.WithCallerSkip(1))
+func WithCallerSkip(n int) Option {
+	return func(l *Logger) {
+		l.callerSkip = max(n, 0)
+	}
+}
+
+// return the source location skip frames above the function calling
+// caller, or an empty string if source locations aren't enabled for
+// either the log file or the console.
+// exported logging methods call this directly with a skip of 1. frames
+// skipped with WithCallerSkip are added to skip.
+func (l *Logger) caller(skip int) string {
+	if !l.out.addSource && (l.handlerOpts == nil || !l.handlerOpts.AddSource) {
+		return ""
+	}
+	_, file, line, ok := runtime.Caller(skip + 1 + l.callerSkip)
+	if !ok {
+		return ""
+	}
+	return formatSource(file, line)
+}
+
+// format a source location as dir/file.go:line
+func formatSource(file string, line int) string {
+	dir, name := filepath.Split(file)
+	return fmt.Sprintf("%s:%d", filepath.Join(filepath.Base(dir), name), line)
+}
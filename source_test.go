@@ -0,0 +1,143 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// the line of the caller's next line
+func nextLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line + 1
+}
+
+func TestSource(t *testing.T) {
+	sink := &memSink{}
+	var console strings.Builder
+	l, _ := NewBufferLogger("source", "1", WithOutput(&console), WithSource(true), WithSinks(sink))
+	child := l.Child("child")
+
+	var lines []int
+	lines = append(lines, nextLine())
+	l.Info("info")
+	lines = append(lines, nextLine())
+	l.Infoln("infoln")
+	lines = append(lines, nextLine())
+	l.InfoWith(map[string]any{"k": 1}, "with fields")
+	lines = append(lines, nextLine())
+	l.InfoContext(context.Background(), "context")
+	lines = append(lines, nextLine())
+	l.ErrorErr(errors.New("boom"), "error")
+	lines = append(lines, nextLine())
+	l.Logf(WARN, "logf")
+	lines = append(lines, nextLine())
+	child.Warn("child")
+	l.Close()
+
+	if len(sink.entries) != len(lines) {
+		t.Fatalf("got %d entries, want %d", len(sink.entries), len(lines))
+	}
+	for i, e := range sink.entries {
+		want := fmt.Sprintf("source_test.go:%d", lines[i])
+		if !strings.HasSuffix(e.Source, want) {
+			t.Errorf("%q has source %q, want it to end in %q", e.Message, e.Source, want)
+		}
+	}
+	if want := fmt.Sprintf("source_test.go:%d", lines[0]); !strings.Contains(console.String(), want) {
+		t.Errorf("console %q doesn't show the source %q", console.String(), want)
+	}
+}
+
+// logs through a helper, like code wrapping the logger
+func logThroughHelper(l *Logger) {
+	l.Info("from helper")
+}
+
+func TestCallerSkip(t *testing.T) {
+	sink := &memSink{}
+	l, _ := NewBufferLogger("source", "1", WithSilentConsole(), WithSource(true), WithCallerSkip(1), WithSinks(sink))
+	line := nextLine()
+	logThroughHelper(l)
+	l.Close()
+	if want := fmt.Sprintf("source_test.go:%d", line); !strings.HasSuffix(sink.entries[0].Source, want) {
+		t.Errorf("source = %q, want the helper's caller %q", sink.entries[0].Source, want)
+	}
+}
+
+// logs through two layers of helpers
+func logThroughTwoHelpers(l *Logger) {
+	logThroughHelper(l)
+}
+
+// logs with each kind of method through a helper
+func logEachWayThroughHelper(l *Logger) {
+	l.Infoln("infoln")
+	l.InfoWith(map[string]any{"k": 1}, "with fields")
+	l.InfoContext(context.Background(), "context")
+	l.ErrorErr(errors.New("boom"), "error")
+	l.Log(WARN, "log")
+	l.Child("child").Warn("child")
+}
+
+func TestCallerSkipLayers(t *testing.T) {
+	sink := &memSink{}
+	l, _ := NewBufferLogger("source", "1", WithSilentConsole(), WithSource(true), WithCallerSkip(2), WithSinks(sink))
+	var lines []int
+	lines = append(lines, nextLine())
+	logThroughTwoHelpers(l)
+	lines = append(lines, nextLine())
+	logThroughTwoHelpers(l.WithFields(map[string]any{"derived": true}))
+	l.Close()
+
+	if len(sink.entries) != len(lines) {
+		t.Fatalf("got %d entries, want %d", len(sink.entries), len(lines))
+	}
+	for i, e := range sink.entries {
+		if want := fmt.Sprintf("source_test.go:%d", lines[i]); !strings.HasSuffix(e.Source, want) {
+			t.Errorf("entry %d source = %q, want the outer helper's caller %q", i, e.Source, want)
+		}
+	}
+}
+
+func TestCallerSkipEachMethod(t *testing.T) {
+	sink := &memSink{}
+	l, _ := NewBufferLogger("source", "1", WithSilentConsole(), WithSource(true), WithCallerSkip(1), WithSinks(sink))
+	line := nextLine()
+	logEachWayThroughHelper(l)
+	l.Close()
+
+	if len(sink.entries) != 6 {
+		t.Fatalf("got %d entries, want 6", len(sink.entries))
+	}
+	want := fmt.Sprintf("source_test.go:%d", line)
+	for _, e := range sink.entries {
+		if !strings.HasSuffix(e.Source, want) {
+			t.Errorf("%q has source %q, want the helper's caller %q", e.Message, e.Source, want)
+		}
+	}
+}
+
+func TestCallerSkipNegative(t *testing.T) {
+	sink := &memSink{}
+	l, _ := NewBufferLogger("source", "1", WithSilentConsole(), WithSource(true), WithCallerSkip(-3), WithSinks(sink))
+	line := nextLine()
+	l.Info("direct")
+	l.Close()
+	if want := fmt.Sprintf("source_test.go:%d", line); !strings.HasSuffix(sink.entries[0].Source, want) {
+		t.Errorf("source = %q, want %q as with no skip", sink.entries[0].Source, want)
+	}
+}
+
+func TestSourceDisabledByDefault(t *testing.T) {
+	sink := &memSink{}
+	l, _ := NewBufferLogger("source", "1", WithSilentConsole(), WithSinks(sink))
+	l.Info("no source")
+	l.Close()
+	if src := sink.entries[0].Source; src != "" {
+		t.Errorf("source = %q, want none", src)
+	}
+}
@@ -0,0 +1,100 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"log/slog"
+	"log/syslog"
+	"sync"
+)
+
+// syslogSink writes entries to syslog, with one connection per component
+// since the tag is fixed per connection.
+type syslogSink struct {
+	network, addr, tag string
+
+	mu      sync.Mutex
+	writers map[string]*syslog.Writer // by component
+}
+
+// NewSyslogSink returns a sink that forwards entries to the syslog server
+// at addr over network, as with syslog.Dial. An empty network and addr
+// connect to the local syslog server. Each entry is tagged with its
+// component followed by tag, such as "Server/myapp". Levels map to
+// syslog severities: DEBUG to LOG_DEBUG, INFO to LOG_INFO, WARN to
+// LOG_WARNING, ERROR to LOG_ERR, and FATAL to LOG_CRIT. Dropped
+// connections are reestablished on the next write.
+//
+// Syslog isn't supported on Windows or Plan 9, where NewSyslogSink always
+// returns an error.
+func NewSyslogSink(network, addr, tag string) (Sink, error) {
+	s := &syslogSink{network: network, addr: addr, tag: tag, writers: make(map[string]*syslog.Writer)}
+	// connect up front so configuration errors are reported right away
+	if _, err := s.writer(""); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// return the writer for a component, connecting if needed.
+// must be called while holding s.mu unless s isn't shared yet.
+func (s *syslogSink) writer(component string) (*syslog.Writer, error) {
+	if w, ok := s.writers[component]; ok {
+		return w, nil
+	}
+	tag := s.tag
+	switch {
+	case component != "" && tag != "":
+		tag = component + "/" + tag
+	case component != "":
+		tag = component
+	}
+	w, err := syslog.Dial(s.network, s.addr, syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, err
+	}
+	s.writers[component] = w
+	return w, nil
+}
+
+func (s *syslogSink) WriteEntry(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, err := s.writer(e.Component)
+	if err != nil {
+		return err
+	}
+	msg := e.Message
+	if e.ID != "" {
+		msg += " id=" + e.ID
+	}
+	if len(e.Fields) > 0 {
+		msg += " " + encodeFields(e.Fields)
+	}
+	// syslog.Writer reconnects and retries once if the write fails
+	sev, _ := parseLevel(e.Level)
+	switch {
+	case sev < int(slog.LevelInfo):
+		return w.Debug(msg)
+	case sev < int(slog.LevelWarn):
+		return w.Info(msg)
+	case sev < int(slog.LevelError):
+		return w.Warning(msg)
+	case sev < int(slogLevelFatal):
+		return w.Err(msg)
+	}
+	return w.Crit(msg)
+}
+
+func (s *syslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var err error
+	for component, w := range s.writers {
+		if cerr := w.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		delete(s.writers, component)
+	}
+	return err
+}
@@ -0,0 +1,11 @@
+//go:build windows || plan9
+
+package logger
+
+import "errors"
+
+// NewSyslogSink always returns an error since syslog isn't supported on
+// Windows or Plan 9.
+func NewSyslogSink(network, addr, tag string) (Sink, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"hash/maphash"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// throttle writes each unique message at most once per ttl, regardless
+// of what's logged in between. messages are tracked by a hash of their
+// level, component, and text so memory doesn't grow with message length,
+// and hashes older than the ttl are pruned once per ttl.
+type throttle struct {
+	ttl    atomic.Int64 // nanoseconds a message is suppressed for after being written. 0 disables
+	mu     sync.Mutex
+	seed   maphash.Seed
+	seen   map[uint64]time.Time // when each message was last written
+	pruned time.Time            // when seen was last pruned
+}
+
+// WithThrottle writes each unique message, with the same level, component,
+// and text, at most once per ttl, dropping repeats within the ttl even if
+// other messages are logged in between, such as to log an error signature
+// once a minute. Unlike WithDedup, repeats aren't counted. Only the log
+// file, hooks, and sinks are throttled; messages are still displayed. The
+// throttle is shared with derived loggers and can be changed or disabled
+// later with SetThrottle. LogBatch isn't throttled. 0 disables throttling,
+// which is the default.
+func WithThrottle(ttl time.Duration) Option {
+	return func(l *Logger) {
+		l.out.throttle.ttl.Store(int64(ttl))
+	}
+}
+
+// SetThrottle changes the ttl set with WithThrottle. 0 disables throttling.
+func (l *Logger) SetThrottle(ttl time.Duration) {
+	l.out.throttle.ttl.Store(int64(ttl))
+}
+
+// reports whether an entry logged at now should be written, recording
+// it as written if so.
+func (t *throttle) allow(e Entry, now time.Time) bool {
+	ttl := time.Duration(t.ttl.Load())
+	if ttl <= 0 {
+		return true
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.seen == nil {
+		t.seed = maphash.MakeSeed()
+		t.seen = make(map[uint64]time.Time)
+		t.pruned = now
+	}
+	if now.Sub(t.pruned) >= ttl {
+		for h, last := range t.seen {
+			if now.Sub(last) >= ttl {
+				delete(t.seen, h)
+			}
+		}
+		t.pruned = now
+	}
+	h := maphash.String(t.seed, e.Level+"\x00"+e.Component+"\x00"+e.Message)
+	if last, ok := t.seen[h]; ok && now.Sub(last) < ttl {
+		return false
+	}
+	t.seen[h] = now
+	return true
+}
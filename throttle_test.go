@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThrottle(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	l, buf := NewBufferLogger("throttle", "1", WithSilentConsole(), WithThrottle(time.Minute),
+		WithClock(func() time.Time { return now }))
+	defer l.Close()
+
+	for i := range 5 {
+		l.Infoln("connection refused")
+		// other messages in between don't reset the throttle
+		l.Info("retrying %d", i)
+		now = now.Add(10 * time.Second)
+	}
+	// more than a minute after the first one was written
+	now = now.Add(15 * time.Second)
+	l.Infoln("connection refused")
+	l.Flush()
+
+	var refused int
+	for _, r := range bufferRecords(t, buf.String()) {
+		if r[3] == "connection refused" {
+			refused++
+		}
+	}
+	if refused != 2 {
+		t.Errorf("wrote %d rows of the throttled message, want 2", refused)
+	}
+	if got := l.DropStats()[DropThrottle]; got != 4 {
+		t.Errorf("throttle drops = %d, want 4", got)
+	}
+}
+
+func TestThrottleKeyedByLevelAndComponent(t *testing.T) {
+	l, buf := NewBufferLogger("throttle", "1", WithSilentConsole(), WithThrottle(time.Hour))
+	defer l.Close()
+	l.Infoln("same text")
+	l.Warnln("same text")
+	l.Child("other").Infoln("same text")
+	l.Infoln("same text")
+	l.Flush()
+	if records := bufferRecords(t, buf.String()); len(records) != 3 {
+		t.Errorf("wrote %d rows, want 3", len(records))
+	}
+}
+
+func TestSetThrottleDisables(t *testing.T) {
+	l, buf := NewBufferLogger("throttle", "1", WithSilentConsole(), WithThrottle(time.Hour))
+	defer l.Close()
+	l.Infoln("repeat")
+	l.Infoln("repeat")
+	l.SetThrottle(0)
+	l.Infoln("repeat")
+	l.Infoln("repeat")
+	l.Flush()
+	if records := bufferRecords(t, buf.String()); len(records) != 3 {
+		t.Errorf("wrote %d rows, want 3", len(records))
+	}
+}
+
+// expired messages are pruned so distinct messages don't grow the map
+// without bound
+func TestThrottlePrunes(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	l, _ := NewBufferLogger("throttle", "1", WithSilentConsole(), WithThrottle(time.Second),
+		WithClock(func() time.Time { return now }))
+	defer l.Close()
+	for i := range 1000 {
+		l.Info("message %d", i)
+		now = now.Add(10 * time.Millisecond)
+	}
+	l.out.throttle.mu.Lock()
+	seen := len(l.out.throttle.seen)
+	l.out.throttle.mu.Unlock()
+	// at most two ttls' worth of messages are held between prunes
+	if seen > 200 {
+		t.Errorf("throttle holds %d messages, want at most 200", seen)
+	}
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"strconv"
+	"time"
+)
+
+// Special time formats for WithTimeFormat and ReadTimeFormat that
+// write timestamps as Unix epoch times rather than using a layout.
+const (
+	TimeFormatUnix      = "unix"      // seconds since the Unix epoch
+	TimeFormatUnixMilli = "unixmilli" // milliseconds since the Unix epoch
+)
+
+// default timestamp layout
+const defaultTimeFormat = time.RFC3339
+
+// WithTimeFormat sets the layout used for entry timestamps, as accepted
+// by time.Time.Format, or one of TimeFormatUnix or TimeFormatUnixMilli.
+// An empty layout uses the default, time.RFC3339. Files written with a
+// custom format should be read using ReadTimeFormat with the same layout.
+func WithTimeFormat(layout string) Option {
+	return func(l *Logger) {
+		if layout == "" {
+			layout = defaultTimeFormat
+		}
+		l.out.timeFormat = layout
+	}
+}
+
+// format a timestamp using the given layout
+func formatTime(t time.Time, layout string) string {
+	switch layout {
+	case TimeFormatUnix:
+		return strconv.FormatInt(t.Unix(), 10)
+	case TimeFormatUnixMilli:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	}
+	return t.Format(layout)
+}
+
+// parse a timestamp written with the given layout
+func parseTime(s string, layout string) (time.Time, error) {
+	switch layout {
+	case TimeFormatUnix, TimeFormatUnixMilli:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if layout == TimeFormatUnix {
+			return time.Unix(n, 0).UTC(), nil
+		}
+		return time.UnixMilli(n).UTC(), nil
+	}
+	return time.Parse(layout, s)
+}
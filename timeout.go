@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// ErrWriteTimeout is recorded, and returned by Err, when a write to the
+// log file doesn't complete within the timeout set with WithWriteTimeout.
+var ErrWriteTimeout = errors.New("write to log file timed out")
+
+// WithWriteTimeout limits how long a write to the log file may take, so a
+// log file on a hung network filesystem can't block every logger sharing
+// it while the write holds the lock. Writes are handed to a goroutine that
+// owns the file, and if one doesn't complete within d it's abandoned: the
+// logger's lock is released and the write fails with ErrWriteTimeout. As
+// with any other failed write, the entry that triggered the write is sent
+// to stderr instead, and other entries that were still buffered are lost.
+// Recovering from a timeout doesn't touch the file, so a file lock
+// taken with WithFileLock is only released once the file is closed.
+//
+// There's no way to cancel a write that's already been started, so an
+// abandoned write may still complete later, and entries written to stderr
+// may then end up in the log file too. Until it completes, further writes
+// fail immediately rather than waiting out the timeout again. Each write
+// is also copied for the goroutine, which adds some overhead to every
+// flush. Opening, rotating, and locking the file aren't covered by the
+// timeout. 0 disables the timeout, which is the default.
+func WithWriteTimeout(d time.Duration) Option {
+	return func(l *Logger) {
+		l.out.writeTimeout = d
+	}
+}
+
+// result of a write made by a timeoutWriter's goroutine
+type writeResult struct {
+	n   int
+	err error
+}
+
+// timeoutWriter writes to a file on a dedicated goroutine so writes can be
+// abandoned if they take too long. it's only used while holding o.mu.
+type timeoutWriter struct {
+	file     *os.File
+	timeout  time.Duration
+	writes   chan []byte
+	results  chan writeResult
+	pending  bool           // whether an abandoned write hasn't completed yet
+	timeouts *atomic.Uint64 // counts writes that timed out or were refused
+}
+
+func newTimeoutWriter(file *os.File, timeout time.Duration, timeouts *atomic.Uint64) *timeoutWriter {
+	t := &timeoutWriter{
+		file:     file,
+		timeout:  timeout,
+		writes:   make(chan []byte),
+		results:  make(chan writeResult, 1),
+		timeouts: timeouts,
+	}
+	go func() {
+		for p := range t.writes {
+			n, err := t.file.Write(p)
+			t.results <- writeResult{n, err}
+		}
+	}()
+	return t
+}
+
+func (t *timeoutWriter) Write(p []byte) (int, error) {
+	if t.pending {
+		select {
+		case <-t.results:
+			t.pending = false
+		default:
+			t.timeouts.Add(1)
+			return 0, fmt.Errorf("%w: a previous write hasn't completed", ErrWriteTimeout)
+		}
+	}
+	// the caller reuses p once Write returns, so the goroutine gets
+	// its own copy in case the write is abandoned.
+	t.writes <- bytes.Clone(p)
+	timer := time.NewTimer(t.timeout)
+	defer timer.Stop()
+	select {
+	case r := <-t.results:
+		return r.n, r.err
+	case <-timer.C:
+		t.pending = true
+		t.timeouts.Add(1)
+		return 0, fmt.Errorf("%w after %s", ErrWriteTimeout, t.timeout)
+	}
+}
+
+// stop the goroutine once any abandoned write completes
+func (t *timeoutWriter) stop() {
+	close(t.writes)
+}
+
+// return the writer for a file, giving it a timeoutWriter if a write
+// timeout is set. the previous file's timeoutWriter is stopped.
+// must be called while holding o.mu.
+func (o *output) fileWriter(file *os.File) io.Writer {
+	if o.writeTimeout <= 0 {
+		return file
+	}
+	if o.timeoutWriter != nil {
+		if o.timeoutWriter.file == file {
+			return o.timeoutWriter
+		}
+		o.timeoutWriter.stop()
+	}
+	o.timeoutWriter = newTimeoutWriter(file, o.writeTimeout, &o.drops.writeTimeout)
+	return o.timeoutWriter
+}
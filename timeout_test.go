@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteTimeoutKeepsTrackedSize(t *testing.T) {
+	tempLogDir(t)
+	l := NewLogger("timeout", "1", WithSilentConsole(), WithWriteTimeout(20*time.Millisecond))
+	defer l.Close()
+
+	// nothing reads from the pipe, so writes block once its buffer fills
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	l.out.mu.Lock()
+	l.out.setFile(w)
+	l.out.size = 123
+	l.out.mu.Unlock()
+
+	for range 4 {
+		l.Infoln(strings.Repeat("x", 64<<10))
+	}
+	if err := l.Err(); !errors.Is(err, ErrWriteTimeout) {
+		t.Fatalf("Err() = %v, want ErrWriteTimeout", err)
+	}
+	l.out.mu.Lock()
+	size, file := l.out.size, l.out.file
+	l.out.mu.Unlock()
+	if file != w {
+		t.Error("log file changed after a write timed out")
+	}
+	if size < 123 {
+		t.Errorf("tracked size = %d after a write timed out, want at least 123", size)
+	}
+}
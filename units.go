@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InfoDur logs msg at LevelInfo with a duration appended, such as
+// InfoDur("request took", d) logging "request took 1.5s". If fields are
+// written to the log file, with WithExtraColumn, JSON output, or a custom
+// formatter, the duration is also attached to the entry as the "duration"
+// field, which is written as a string in the same form.
+func (l *Logger) InfoDur(msg string, d time.Duration) {
+	if !l.enabled(INFO) {
+		return
+	}
+	l.emitUnit(INFO, "duration", d, msg+" "+d.String(), l.caller(1))
+}
+
+// InfoBytes logs msg at LevelInfo with a size in bytes appended in a
+// human readable form, such as InfoBytes("uploaded", 3200000) logging
+// "uploaded 3.2MB". Sizes use decimal units (1KB is 1000 bytes) with one
+// decimal place. Like InfoDur, the exact number of bytes is also attached
+// to the entry as the "bytes" field if fields are written to the log file.
+func (l *Logger) InfoBytes(msg string, n int64) {
+	if !l.enabled(INFO) {
+		return
+	}
+	l.emitUnit(INFO, "bytes", n, msg+" "+formatBytes(n), l.caller(1))
+}
+
+// log a message with a value already formatted into it, attaching the raw
+// value as a field only if fields are written to the log file. otherwise
+// the field would only add an Extra column to entries that have it.
+func (l *Logger) emitUnit(level string, key string, value any, msg string, src string) {
+	if !l.out.extraColumn && l.out.format != FormatJSON && l.out.formatter == nil {
+		l.emit(level, msg, src)
+		return
+	}
+	l.emitWith(level, map[string]any{key: value}, msg, src)
+}
+
+// format a number of bytes with decimal units, such as 512B, 1KB, or 3.2MB
+func formatBytes(n int64) string {
+	const units = "KMGTPE"
+	if n > -1000 && n < 1000 {
+		return strconv.FormatInt(n, 10) + "B"
+	}
+	f := float64(n)
+	i := -1
+	for (f <= -999.95 || f >= 999.95) && i < len(units)-1 {
+		f /= 1000
+		i++
+	}
+	s := strconv.FormatFloat(f, 'f', 1, 64)
+	return strings.TrimSuffix(s, ".0") + units[i:i+1] + "B"
+}
@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFormatBytes(t *testing.T) {
+	for n, want := range map[int64]string{
+		0:             "0B",
+		999:           "999B",
+		1000:          "1KB",
+		1500:          "1.5KB",
+		3_200_000:     "3.2MB",
+		999_949:       "999.9KB",
+		999_950:       "1MB",
+		5_000_000_000: "5GB",
+		-2048:         "-2KB",
+		math.MaxInt64: "9.2EB",
+	} {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestInfoDurAndBytes(t *testing.T) {
+	tempLogDir(t)
+	sink := &memSink{}
+	l := NewLogger("units", "1", WithSilentConsole(), WithExtraColumn(true), WithSinks(sink))
+	l.InfoDur("request took", 1500*time.Millisecond)
+	l.InfoDur("cache hit in", 250*time.Microsecond)
+	l.InfoBytes("uploaded", 3_200_000)
+	l.InfoBytes("header is", 512)
+	entries := readLog(t, l)
+	l.Close()
+
+	want := []struct {
+		msg   string
+		key   string
+		value any
+	}{
+		{"request took 1.5s", "duration", 1500 * time.Millisecond},
+		{"cache hit in 250µs", "duration", 250 * time.Microsecond},
+		{"uploaded 3.2MB", "bytes", int64(3_200_000)},
+		{"header is 512B", "bytes", int64(512)},
+	}
+	if len(entries) != len(want) || len(sink.entries) != len(want) {
+		t.Fatalf("got %d entries in the file and %d in the sink, want %d", len(entries), len(sink.entries), len(want))
+	}
+	for i, w := range want {
+		// the raw value is kept in the field, in the file and the sink
+		for _, e := range []Entry{entries[i], sink.entries[i]} {
+			if e.Message != w.msg || e.Level != INFO || e.Fields[w.key] != w.value {
+				t.Errorf("entry = %q %s %#v, want %q INFO with %s %#v", e.Message, e.Level, e.Fields, w.msg, w.key, w.value)
+			}
+		}
+	}
+}
+
+func TestInfoDurBelowLevel(t *testing.T) {
+	sink := &memSink{}
+	l, _ := NewBufferLogger("units", "1", WithSilentConsole(), WithSinks(sink))
+	l.SetLevel(WARN)
+	l.InfoDur("request took", time.Second)
+	l.InfoBytes("uploaded", 1000)
+	l.Close()
+	if len(sink.entries) != 0 {
+		t.Errorf("got %d entries below the level, want none", len(sink.entries))
+	}
+}
+
+// without somewhere to write fields, the value is only in the message
+func TestInfoDurWithoutFields(t *testing.T) {
+	l, buf := NewBufferLogger("units", "1", WithSilentConsole())
+	l.InfoDur("request took", 1500*time.Millisecond)
+	l.InfoBytes("uploaded", 3_200_000)
+	l.Close()
+	records := bufferRecords(t, buf.String())
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	for i, want := range []string{"request took 1.5s", "uploaded 3.2MB"} {
+		if r := records[i]; len(r) != 5 || r[3] != want {
+			t.Errorf("record = %q, want message %q and no fields column", r, want)
+		}
+	}
+}
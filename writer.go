@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// levelWriter records each line written to it as a log entry.
+type levelWriter struct {
+	l     *Logger
+	level string
+	mu    sync.Mutex   // guards buf
+	buf   bytes.Buffer // incomplete line from a previous write
+}
+
+// Writer returns an io.Writer that displays and records each line written
+// to it as an entry at the given level, without the trailing newline. A
+// single write may contain several lines; text after the last newline is
+// held until a later write completes the line. This lets packages that
+// write to an io.Writer, such as the standard log package, log through l:
+//
+//	log.SetFlags(0) // the logger adds its own timestamp
+//	log.SetOutput(l.Writer(logger.INFO))
+func (l *Logger) Writer(level string) io.Writer {
+	return &levelWriter{l: l, level: level}
+}
+
+func (w *levelWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// no newline yet, keep the partial line for the next write
+			w.buf.Reset()
+			w.buf.WriteString(line)
+			break
+		}
+		line = line[:len(line)-1]
+		if len(line) > 0 && line[len(line)-1] == '\r' {
+			line = line[:len(line)-1]
+		}
+		if w.l.enabled(w.level) {
+			w.l.emit(w.level, line, "")
+		}
+	}
+	return len(p), nil
+}